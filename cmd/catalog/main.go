@@ -0,0 +1,148 @@
+// Command catalog imports and exports the product catalog as CSV or XLSX.
+//
+//	catalog import [-dry-run] products.xlsx
+//	catalog export products.csv
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"international_site/internal/config"
+	"international_site/internal/logger"
+	"international_site/internal/service"
+	"international_site/internal/storage/lts"
+	"international_site/pkg/sheet"
+	"log"
+	"os"
+	"time"
+)
+
+func getConfigPath() string {
+	env := os.Getenv("APP_ENV")
+	if env == "" {
+		env = "local"
+	}
+
+	return fmt.Sprintf("app_configs/config_%s.yaml", env)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage:")
+	fmt.Fprintln(os.Stderr, "  catalog import [-dry-run] <file.csv|file.xlsx>")
+	fmt.Fprintln(os.Stderr, "  catalog export <file.csv|file.xlsx>")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	cfg, err := config.Load(getConfigPath())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	logger, err := logger.New(cfg.Logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	storage, err := lts.New(cfg.Database.Master)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	svc := service.New(logger, storage, &cfg.Service, time.Now)
+
+	switch os.Args[1] {
+	case "import":
+		os.Exit(runImport(svc, os.Args[2:]))
+	case "export":
+		os.Exit(runExport(svc, os.Args[2:]))
+	default:
+		usage()
+	}
+}
+
+// runImport prints the import report as JSON and exits with 1 when the file has errors.
+func runImport(svc *service.Instance, args []string) int {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "only report what would change")
+
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		usage()
+	}
+
+	path := flags.Arg(0)
+
+	format, err := sheet.FormatOf(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	defer file.Close()
+
+	rows, err := sheet.Read(file, format)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	report, err := svc.ImportCatalog(rows, *dryRun, "catalog")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(report); err != nil {
+		log.Fatal(err)
+	}
+
+	if report.HasErrors() {
+		return 1
+	}
+
+	return 0
+}
+
+func runExport(svc *service.Instance, args []string) int {
+	if len(args) != 1 {
+		usage()
+	}
+
+	format, err := sheet.FormatOf(args[0])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rows, err := svc.ExportCatalog()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	file, err := os.Create(args[0])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := sheet.Write(file, format, rows); err != nil {
+		file.Close()
+		log.Fatal(err)
+	}
+
+	if err := file.Close(); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("exported %d products to %s", len(rows)-1, args[0])
+
+	return 0
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"international_site/internal/config"
 	"international_site/internal/handler"
@@ -81,6 +82,9 @@ func main() {
 	router := gin.Default()
 	service := service.New(logger, lts, &cfg.Service, nowFunc)
 
+	go service.RunNewsScheduler(context.Background())
+	go service.RunVocabularyRefresh(context.Background())
+
 	server := handler.New(service, serverCfg, router, logger)
 
 	if err := server.ListenAndServe(); err != nil {
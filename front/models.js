@@ -55,7 +55,7 @@ class Product {
         this.id = data.id;
         this.categoryId = data.category_id;
         this.sku = data.sku;
-        this.imageUrl = data.image_url;
+        this.imageUrl = data.primary_image_url || data.image_url;
         this.fileUrl = data.file_url;
         this.sortOrder = data.sort_order;
         this.translationsArray = data.translations || [];
@@ -87,7 +87,7 @@ class News {
     constructor(data) {
         this.id = data.id;
         this.imageUrl = data.image_url;
-        this.published = data.published;
+        this.status = data.status;
         this.createdAt = data.created_at;
         this.updatedAt = data.updated_at;
         this.translations = this.createTranslationsMap;
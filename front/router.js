@@ -560,9 +560,14 @@ class Router {
                     
                     <div class="search-info">
                         <p>${locale === 'ru' ? 'Найдено' : 
-                            locale === 'en' ? 'Found' : 'Znaleziono'} ${data.count || 0} 
+                            locale === 'en' ? 'Found' : 'Znaleziono'} ${data.total || 0} 
                             ${locale === 'ru' ? 'результатов по запросу' : 
                              locale === 'en' ? 'results for' : 'wyników dla'} "${query}"</p>
+                        ${data.did_you_mean ? `
+                            <p class="did-you-mean">${locale === 'ru' ? 'Возможно, вы имели в виду' : 
+                                locale === 'en' ? 'Did you mean' : 'Czy chodziło Ci o'}: 
+                                <a href="/${locale}/search?q=${encodeURIComponent(data.did_you_mean)}">${data.did_you_mean}</a></p>
+                        ` : ''}
                     </div>
                     
                     ${data.results && data.results.length > 0 ? `
@@ -570,7 +575,7 @@ class Router {
                             ${data.results.map(result => `
                                 <div class="search-result card">
                                     <h3><a href="${result.url}">${result.title}</a></h3>
-                                    <p>${result.excerpt}</p>
+                                    <p>${result.snippet || result.description}</p>
                                     <div class="result-type">${result.type}</div>
                                 </div>
                             `).join('')}
@@ -37,6 +37,8 @@ func (a *App) ToString() string {
 	cfgCopy.Database.Master.Host = censorship
 	cfgCopy.Database.Master.Password = censorship
 	cfgCopy.Database.Master.Username = censorship
+	cfgCopy.Server.AdminTokens = nil
+	cfgCopy.Service.PreviewSecret = censorship
 
 	return fmt.Sprintf("%+v", cfgCopy)
 }
@@ -51,6 +53,8 @@ type Server struct {
 	IdleTimeout    time.Duration `yaml:"idle_timeout"`
 	Timezone       string        `yaml:"timezone"`
 	UsernameLength int           `yaml:"min_username_length"`
+	// AdminTokens maps bearer tokens accepted by the admin API to admin names
+	AdminTokens map[string]string `yaml:"admin_tokens"`
 
 	Metrics     *metrics.Metrics
 	Tracer      opentracing.Tracer
@@ -58,8 +62,21 @@ type Server struct {
 }
 
 type Service struct {
-	DefaultLang string
-	SiteURL     string
+	DefaultLang string `yaml:"default_lang"`
+	SiteURL     string `yaml:"site_url"`
+	// CompanyName is printed in the header of generated datasheets
+	CompanyName string `yaml:"company_name"`
+	// MediaDir is the local directory that media URLs such as /images/... are served from
+	MediaDir string `yaml:"media_dir"`
+	// PreviewSecret signs the preview links that show unpublished content
+	PreviewSecret string `yaml:"preview_secret"`
+	// SchedulerInterval is how often scheduled news are checked; one minute by default
+	SchedulerInterval time.Duration `yaml:"scheduler_interval"`
+	// VocabularyInterval is how often the search vocabulary is rebuilt; one hour by default
+	VocabularyInterval time.Duration `yaml:"vocabulary_interval"`
+	// DisplayUnits maps a locale to dimension -> unit symbol, e.g. en: {length: in},
+	// for specs shown in other than the metric base units
+	DisplayUnits map[string]map[string]string `yaml:"display_units"`
 }
 
 // Tracer holds tracing configuration details.
@@ -184,5 +201,6 @@ func NewServer(
 		Metrics:      metrics,
 		EventLogger:  eventLogger,
 		Timezone:     cfg.Timezone,
+		AdminTokens:  cfg.AdminTokens,
 	}
 }
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToStringMasksSecrets(t *testing.T) {
+	cfg := &App{}
+	cfg.Database.Master.Host = "db.internal"
+	cfg.Database.Master.Username = "catalog"
+	cfg.Database.Master.Password = "db-password"
+	cfg.Server.AdminTokens = map[string]string{"admin-token": "alice"}
+	cfg.Service.PreviewSecret = "preview-secret"
+
+	text := cfg.ToString()
+
+	for _, secret := range []string{"db.internal", "catalog", "db-password", "admin-token", "preview-secret"} {
+		if strings.Contains(text, secret) {
+			t.Errorf("ToString() reveals %q: %s", secret, text)
+		}
+	}
+
+	if cfg.Service.PreviewSecret != "preview-secret" {
+		t.Errorf("ToString() changed the config: PreviewSecret = %q", cfg.Service.PreviewSecret)
+	}
+}
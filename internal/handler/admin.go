@@ -0,0 +1,359 @@
+package handler
+
+import (
+	"international_site/internal/types"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) AdminCreateProduct(c *gin.Context) {
+	var req types.ProductRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := s.service.CreateProduct(req, adminName(c))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(201, gin.H{"id": id})
+}
+
+func (s *Server) AdminUpdateProduct(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(400, gin.H{"error": "invalid product id"})
+		return
+	}
+
+	var req types.ProductRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.service.UpdateProduct(uint(id), req, adminName(c)); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"id": id})
+}
+
+func (s *Server) AdminDeleteProduct(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(400, gin.H{"error": "invalid product id"})
+		return
+	}
+
+	if err := s.service.DeleteProduct(uint(id), adminName(c)); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Status(204)
+}
+
+func (s *Server) AdminSetProductOptions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(400, gin.H{"error": "invalid product id"})
+		return
+	}
+
+	var req types.ProductOptionsRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.service.SetProductOptions(uint(id), req, adminName(c)); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"id": id})
+}
+
+func (s *Server) AdminCreateCategory(c *gin.Context) {
+	var req types.CategoryRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := s.service.CreateCategory(req)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(201, gin.H{"id": id})
+}
+
+func (s *Server) AdminUpdateCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(400, gin.H{"error": "invalid category id"})
+		return
+	}
+
+	var req types.CategoryRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.service.UpdateCategory(uint(id), req); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"id": id})
+}
+
+func (s *Server) AdminDeleteCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(400, gin.H{"error": "invalid category id"})
+		return
+	}
+
+	if err := s.service.DeleteCategory(uint(id)); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Status(204)
+}
+
+// AdminProductStock returns the quantity and reservations of a product per
+// warehouse; the public API shows only availability and stock_level.
+func (s *Server) AdminProductStock(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(400, gin.H{"error": "invalid product id"})
+		return
+	}
+
+	stock, err := s.service.GetProductStock(uint(id))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"stock": stock})
+}
+
+func (s *Server) AdminUpdateStock(c *gin.Context) {
+	var req types.StockUpdateRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := s.service.UpdateStock(req)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"updated": updated})
+}
+
+// AdminSetStatus changes the publication status of a product, page, news item or document.
+func (s *Server) AdminSetStatus(itemType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil || id <= 0 {
+			c.JSON(400, gin.H{"error": "invalid " + itemType + " id"})
+			return
+		}
+
+		var req types.StatusRequest
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := s.service.SetStatus(itemType, uint(id), req.Status, adminName(c)); err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(200, gin.H{"id": id, "status": req.Status})
+	}
+}
+
+// AdminCreatePreview returns a signed link showing the item before it is published.
+func (s *Server) AdminCreatePreview(itemType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil || id <= 0 {
+			c.JSON(400, gin.H{"error": "invalid " + itemType + " id"})
+			return
+		}
+
+		preview, err := s.service.CreatePreview(c.Query("locale"), itemType, uint(id))
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(200, preview)
+	}
+}
+
+// AdminSetNewsSchedule sets when a published news item appears and disappears.
+func (s *Server) AdminSetNewsSchedule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(400, gin.H{"error": "invalid news id"})
+		return
+	}
+
+	var req types.NewsScheduleRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.service.SetNewsSchedule(uint(id), req, adminName(c)); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"id": id})
+}
+
+func (s *Server) AdminUpdatePage(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(400, gin.H{"error": "invalid page id"})
+		return
+	}
+
+	var req types.PageRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.service.UpdatePage(uint(id), req, adminName(c)); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"id": id})
+}
+
+func (s *Server) AdminUpdateNews(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(400, gin.H{"error": "invalid news id"})
+		return
+	}
+
+	var req types.NewsRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.service.UpdateNews(uint(id), req, adminName(c)); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"id": id})
+}
+
+// AdminRevisions lists the revisions of a product, page or news item, newest first.
+func (s *Server) AdminRevisions(itemType string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil || id <= 0 {
+			c.JSON(400, gin.H{"error": "invalid " + itemType + " id"})
+			return
+		}
+
+		revisions, err := s.service.GetRevisions(itemType, uint(id))
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(200, gin.H{"revisions": revisions})
+	}
+}
+
+func (s *Server) AdminRevision(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(400, gin.H{"error": "invalid revision id"})
+		return
+	}
+
+	revision, err := s.service.GetRevision(uint(id))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(200, revision)
+}
+
+// AdminCompareRevisions diffs two revisions of the same item: ?from=1&to=2.
+func (s *Server) AdminCompareRevisions(c *gin.Context) {
+	from, err := strconv.Atoi(c.Query("from"))
+	if err != nil || from <= 0 {
+		c.JSON(400, gin.H{"error": "invalid from revision id"})
+		return
+	}
+
+	to, err := strconv.Atoi(c.Query("to"))
+	if err != nil || to <= 0 {
+		c.JSON(400, gin.H{"error": "invalid to revision id"})
+		return
+	}
+
+	comparison, err := s.service.CompareRevisions(uint(from), uint(to))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(200, comparison)
+}
+
+// AdminRestoreRevision writes the content of a revision back to its item.
+func (s *Server) AdminRestoreRevision(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(400, gin.H{"error": "invalid revision id"})
+		return
+	}
+
+	revision, err := s.service.RestoreRevision(uint(id), adminName(c))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"type": revision.EntityType, "id": revision.EntityID, "restored": revision.ID})
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"international_site/pkg/sheet"
+	"mime"
+
+	"github.com/gin-gonic/gin"
+)
+
+var catalogContentTypes = map[sheet.Format]string{
+	sheet.CSV:  "text/csv; charset=utf-8",
+	sheet.XLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// AdminImportCatalog reads a CSV or XLSX spreadsheet from the "file" form field.
+// With dry_run=true it only reports what would change.
+func (s *Server) AdminImportCatalog(c *gin.Context) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(400, gin.H{"error": "file is required"})
+		return
+	}
+
+	format, err := sheet.FormatOf(header.Filename)
+	if value := c.Query("format"); value != "" {
+		format, err = sheet.ParseFormat(value)
+	}
+
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	defer file.Close()
+
+	rows, err := sheet.Read(file, format)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "cannot read " + string(format) + ": " + err.Error()})
+		return
+	}
+
+	report, err := s.service.ImportCatalog(rows, queryBool(c, "dry_run", false), adminName(c))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	if report.HasErrors() {
+		c.JSON(400, report)
+		return
+	}
+
+	c.JSON(200, report)
+}
+
+func (s *Server) AdminExportCatalog(c *gin.Context) {
+	format, err := sheet.ParseFormat(c.DefaultQuery("format", string(sheet.CSV)))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	rows, err := s.service.ExportCatalog()
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", catalogContentTypes[format])
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": "catalog." + string(format)}))
+
+	if err := sheet.Write(c.Writer, format, rows); err != nil {
+		_ = c.Error(err)
+	}
+}
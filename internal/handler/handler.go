@@ -2,7 +2,11 @@ package handler
 
 import (
 	"international_site/internal/types"
+	"mime"
+	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,7 +15,7 @@ func (s *Server) Page(c *gin.Context) {
 	locale := getLocale(c)
 	slug := c.Param("slug")
 
-	page, err := s.service.GetPageBySlug(slug, locale)
+	page, err := s.service.GetPageBySlug(slug, locale, c.Query("preview"))
 	if err != nil {
 		abortWithError(c, err)
 		return
@@ -42,6 +46,20 @@ func (s *Server) ProductsPage(c *gin.Context) {
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		products, next, err := s.service.GetProductsAfter(locale, cursor, limit)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"items":       products,
+			"next_cursor": next,
+		})
+		return
+	}
+
 	products, total, err := s.service.GetProducts(locale, offset, limit)
 	if err != nil {
 		abortWithError(c, err)
@@ -56,7 +74,7 @@ func (s *Server) ProductsPage(c *gin.Context) {
 
 func (s *Server) ProductsByCategory(c *gin.Context) {
 	locale := getLocale(c)
-	slug := c.Param("slug")
+	slug := c.Param("category")
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 
@@ -66,7 +84,24 @@ func (s *Server) ProductsByCategory(c *gin.Context) {
 		return
 	}
 
-	products, total, err := s.service.GetProductsByCategory(locale, category.ID, offset, limit)
+	includeDescendants := queryBool(c, "include_descendants", true)
+
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		products, next, err := s.service.GetProductsByCategoryAfter(locale, category.ID, includeDescendants, cursor, limit)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"category":    category,
+			"items":       products,
+			"next_cursor": next,
+		})
+		return
+	}
+
+	products, total, err := s.service.GetProductsByCategory(locale, category.ID, includeDescendants, offset, limit)
 	if err != nil {
 		abortWithError(c, err)
 		return
@@ -83,7 +118,7 @@ func (s *Server) ProductDetail(c *gin.Context) {
 	locale := getLocale(c)
 	id, _ := strconv.Atoi(c.Param("id"))
 
-	product, err := s.service.GetProductByID(locale, uint(id))
+	product, err := s.service.GetProductDetail(locale, uint(id), c.Query("preview"))
 	if err != nil || product == nil {
 		c.Status(404)
 		return
@@ -92,11 +127,52 @@ func (s *Server) ProductDetail(c *gin.Context) {
 	c.JSON(200, product)
 }
 
+// ProductDatasheet serves the generated PDF and answers 304 while the product is unchanged.
+func (s *Server) ProductDatasheet(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.Status(404)
+		return
+	}
+
+	datasheet, err := s.service.GetProductDatasheet(getLocale(c), uint(id))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.Header("ETag", datasheet.ETag)
+	c.Header("Last-Modified", datasheet.ModifiedAt.UTC().Format(http.TimeFormat))
+	c.Header("Cache-Control", "public, no-cache")
+
+	if c.GetHeader("If-None-Match") == datasheet.ETag {
+		c.Status(304)
+		return
+	}
+
+	c.Header("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": datasheet.FileName}))
+	c.Data(200, "application/pdf", datasheet.Content)
+}
+
 func (s *Server) NewsPage(c *gin.Context) {
 	locale := getLocale(c)
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		items, next, err := s.service.GetNewsAfter(locale, cursor, limit)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"items":       items,
+			"next_cursor": next,
+		})
+		return
+	}
+
 	items, total, err := s.service.GetNews(locale, offset, limit)
 	if err != nil {
 		abortWithError(c, err)
@@ -113,7 +189,7 @@ func (s *Server) NewsDetail(c *gin.Context) {
 	locale := getLocale(c)
 	id, _ := strconv.Atoi(c.Param("id"))
 
-	item, err := s.service.GetNewsByID(locale, uint(id))
+	item, err := s.service.GetNewsByID(locale, uint(id), c.Query("preview"))
 	if err != nil || item == nil {
 		c.Status(404)
 		return
@@ -148,63 +224,186 @@ func (s *Server) ContactsPage(c *gin.Context) {
 	c.JSON(200, items)
 }
 
+// SearchPage and APISearch return one ranked page of results:
+// ?q=&type=product|news|page|document&offset=&limit=, or &cursor= for keyset pages
 func (s *Server) SearchPage(c *gin.Context) {
+	s.search(c, 20)
+}
+
+func (s *Server) APISearch(c *gin.Context) {
+	s.search(c, 10)
+}
+
+func (s *Server) search(c *gin.Context, defaultLimit int) {
 	locale := getLocale(c)
-	query := c.Query("q")
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+
+	var response *types.SearchResponse
+	var err error
+
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		response, err = s.service.SearchAfter(locale, c.Query("q"), c.Query("type"), cursor, limit)
+	} else {
+		response, err = s.service.Search(locale, c.Query("q"), c.Query("type"), offset, limit)
+	}
 
-	results, err := s.service.SearchAll(locale, query)
 	if err != nil {
 		abortWithError(c, err)
 		return
 	}
 
-	c.JSON(200, results)
+	c.JSON(200, response)
 }
 
-func (s *Server) APISearch(c *gin.Context) {
+// APISuggest completes the search box as the visitor types: ?q=&limit=
+func (s *Server) APISuggest(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "5"))
+	if limit <= 0 || limit > 20 {
+		limit = 5
+	}
+
+	start := time.Now()
+	suggestions, err := s.service.Suggest(getLocale(c), c.Query("q"), limit)
+	s.config.Metrics.SuggestLatency.Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(200, suggestions)
+}
+
+func (s *Server) APICategoryTree(c *gin.Context) {
 	locale := getLocale(c)
-	query := c.Query("q")
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 
-	results, err := s.service.SearchAPI(locale, query, limit)
+	tree, err := s.service.GetCategoryTree(locale)
 	if err != nil {
 		abortWithError(c, err)
 		return
 	}
 
-	c.JSON(200, results)
+	c.JSON(200, tree)
 }
 
 func (s *Server) APIProductsFilter(c *gin.Context) {
-	locale := getLocale(c)
 	categoryID, _ := strconv.Atoi(c.Query("category_id"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 
-	search := c.Query("search")
-	sortBy := c.Query("sort_by")
-	sortOrder := c.Query("sort_order")
+	filter := types.ProductFilter{
+		Locale:             getLocale(c),
+		CategoryID:         uint(categoryID),
+		IncludeDescendants: queryBool(c, "include_descendants", true),
+		Search:             c.Query("search"),
+		InStock:            queryBool(c, "in_stock", false),
+		Specs:              bracketParams(c, "spec"),
+		SpecRanges:         specRanges(c),
+		SortBy:             c.Query("sort_by"),
+		SortOrder:          c.Query("sort_order"),
+		Offset:             offset,
+		Limit:              limit,
+	}
+
+	facets, err := s.service.GetSpecFacets(filter)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		items, next, err := s.service.FilterProductsAfter(filter, cursor)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"items":       items,
+			"next_cursor": next,
+			"facets":      facets,
+		})
+		return
+	}
 
-	items, total, err := s.service.FilterProducts(
-		locale,
-		uint(categoryID),
-		search,
-		sortBy,
-		sortOrder,
-		offset,
-		limit,
-	)
+	items, total, err := s.service.FilterProducts(filter)
 	if err != nil {
 		abortWithError(c, err)
 		return
 	}
 
 	c.JSON(200, gin.H{
-		"items": items,
-		"total": total,
+		"items":  items,
+		"total":  total,
+		"facets": facets,
 	})
 }
 
+func (s *Server) APICompareProducts(c *gin.Context) {
+	var ids []uint
+
+	for _, part := range strings.Split(c.Query("ids"), ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+
+		id, err := strconv.Atoi(part)
+		if err != nil || id <= 0 {
+			c.JSON(400, gin.H{"error": "invalid product id " + strconv.Quote(part)})
+			return
+		}
+
+		ids = append(ids, uint(id))
+	}
+
+	comparison, err := s.service.CompareProducts(getLocale(c), ids)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(200, comparison)
+}
+
+func (s *Server) APIRelatedProducts(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(400, gin.H{"error": "invalid product id"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "8"))
+	if limit <= 0 || limit > 50 {
+		limit = 8
+	}
+
+	items, err := s.service.GetRelatedProducts(getLocale(c), uint(id), limit)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"items": items})
+}
+
+// APIBreadcrumbs returns the navigation trail for a product, category, news item or page.
+func (s *Server) APIBreadcrumbs(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil || id <= 0 {
+		c.JSON(400, gin.H{"error": "invalid id"})
+		return
+	}
+
+	items, err := s.service.GetBreadcrumbs(getLocale(c), c.Query("type"), uint(id))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	c.JSON(200, gin.H{"items": items})
+}
+
 func (s *Server) SubmitFeedback(c *gin.Context) {
 	s.APISubmitFeedback(c)
 }
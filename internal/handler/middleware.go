@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"crypto/subtle"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -54,3 +56,27 @@ func (s *Server) tracingMiddleware(c *gin.Context) {
 	span.SetTag("http.status_code", c.Writer.Status())
 	span.LogKV("event", "completed request")
 }
+
+const adminContextKey = "admin"
+
+// adminAuthMiddleware accepts requests carrying one of the configured admin bearer tokens.
+func (s *Server) adminAuthMiddleware(c *gin.Context) {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if ok && token != "" {
+		for known, name := range s.config.AdminTokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(known)) == 1 {
+				c.Set(adminContextKey, name)
+				c.Next()
+
+				return
+			}
+		}
+	}
+
+	c.AbortWithStatusJSON(401, gin.H{"error": "unauthorized"})
+}
+
+// adminName returns the name of the admin authenticated by adminAuthMiddleware.
+func adminName(c *gin.Context) string {
+	return c.GetString(adminContextKey)
+}
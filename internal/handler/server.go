@@ -86,6 +86,7 @@ func (s *Server) registerRoutes(baseURL string) {
 	s.router.GET("/:locale/products", s.ProductsPage)
 	s.router.GET("/:locale/products/:category", s.ProductsByCategory)
 	s.router.GET("/:locale/product/:id", s.ProductDetail)
+	s.router.GET("/:locale/product/:id/datasheet.pdf", s.ProductDatasheet)
 
 	s.router.GET("/:locale/news", s.NewsPage)
 	s.router.GET("/:locale/news/:id", s.NewsDetail)
@@ -101,10 +102,46 @@ func (s *Server) registerRoutes(baseURL string) {
 	api := s.router.Group("/api/:locale")
 	{
 		api.GET("/search", s.APISearch)
+		api.GET("/search/suggest", s.APISuggest)
+		api.GET("/categories/tree", s.APICategoryTree)
 		api.GET("/products/filter", s.APIProductsFilter)
+		api.GET("/products/compare", s.APICompareProducts)
+		api.GET("/product/:id/related", s.APIRelatedProducts)
+		api.GET("/breadcrumbs", s.APIBreadcrumbs)
 		api.POST("/feedback", s.APISubmitFeedback)
 	}
 
+	admin := s.router.Group("/admin/api", s.adminAuthMiddleware)
+	{
+		admin.POST("/products", s.AdminCreateProduct)
+		admin.PUT("/products/:id", s.AdminUpdateProduct)
+		admin.DELETE("/products/:id", s.AdminDeleteProduct)
+		admin.PUT("/products/:id/options", s.AdminSetProductOptions)
+		admin.PUT("/products/:id/status", s.AdminSetStatus(service.ItemProduct))
+		admin.GET("/products/:id/revisions", s.AdminRevisions(service.ItemProduct))
+		admin.POST("/products/:id/preview", s.AdminCreatePreview(service.ItemProduct))
+		admin.PUT("/pages/:id", s.AdminUpdatePage)
+		admin.PUT("/pages/:id/status", s.AdminSetStatus(service.ItemPage))
+		admin.GET("/pages/:id/revisions", s.AdminRevisions(service.ItemPage))
+		admin.POST("/pages/:id/preview", s.AdminCreatePreview(service.ItemPage))
+		admin.PUT("/news/:id", s.AdminUpdateNews)
+		admin.PUT("/news/:id/status", s.AdminSetStatus(service.ItemNews))
+		admin.GET("/news/:id/revisions", s.AdminRevisions(service.ItemNews))
+		admin.POST("/news/:id/preview", s.AdminCreatePreview(service.ItemNews))
+		admin.PUT("/news/:id/schedule", s.AdminSetNewsSchedule)
+		admin.PUT("/documents/:id/status", s.AdminSetStatus(service.ItemDocument))
+		admin.GET("/revisions/compare", s.AdminCompareRevisions)
+		admin.GET("/revisions/:id", s.AdminRevision)
+		admin.POST("/revisions/:id/restore", s.AdminRestoreRevision)
+		admin.POST("/categories", s.AdminCreateCategory)
+		admin.PUT("/categories/:id", s.AdminUpdateCategory)
+		admin.DELETE("/categories/:id", s.AdminDeleteCategory)
+		admin.PUT("/stock", s.AdminUpdateStock)
+		admin.GET("/products/:id/stock", s.AdminProductStock)
+		admin.POST("/catalog/import", s.AdminImportCatalog)
+		admin.GET("/catalog/export", s.AdminExportCatalog)
+	}
+
 	s.router.NoRoute(s.NotFoundPage)
 
 	r.Use(s.tracingMiddleware)
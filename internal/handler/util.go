@@ -1,6 +1,14 @@
 package handler
 
-import "github.com/gin-gonic/gin"
+import (
+	"errors"
+	"international_site/internal/service"
+	"international_site/internal/types"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
 
 func getLocale(c *gin.Context) string {
 	locale := c.Param("locale")
@@ -10,6 +18,81 @@ func getLocale(c *gin.Context) string {
 	return locale
 }
 
+// queryBool parses a boolean query parameter, falling back to def when it is absent or malformed.
+func queryBool(c *gin.Context, key string, def bool) bool {
+	value, err := strconv.ParseBool(c.Query(key))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// bracketParams collects <prefix>[<name>]=<value> query parameters by name.
+func bracketParams(c *gin.Context, prefix string) map[string][]string {
+	params := make(map[string][]string)
+
+	for key, values := range c.Request.URL.Query() {
+		name, ok := strings.CutPrefix(key, prefix+"[")
+		if !ok {
+			continue
+		}
+
+		name, ok = strings.CutSuffix(name, "]")
+		if !ok || name == "" {
+			continue
+		}
+
+		for _, value := range values {
+			if value != "" {
+				params[name] = append(params[name], value)
+			}
+		}
+	}
+
+	return params
+}
+
+// specRanges collects spec_min[<name>], spec_max[<name>] and spec_unit[<name>] query parameters.
+func specRanges(c *gin.Context) map[string]types.SpecRange {
+	ranges := make(map[string]types.SpecRange)
+
+	bound := func(prefix string, set func(r *types.SpecRange, value float64)) {
+		for name, values := range bracketParams(c, prefix) {
+			value, err := strconv.ParseFloat(values[0], 64)
+			if err != nil {
+				continue
+			}
+
+			r := ranges[name]
+			set(&r, value)
+			ranges[name] = r
+		}
+	}
+
+	bound("spec_min", func(r *types.SpecRange, value float64) { r.Min = &value })
+	bound("spec_max", func(r *types.SpecRange, value float64) { r.Max = &value })
+
+	for name, values := range bracketParams(c, "spec_unit") {
+		if r, ok := ranges[name]; ok {
+			r.Unit = values[0]
+			ranges[name] = r
+		}
+	}
+
+	return ranges
+}
+
 func abortWithError(c *gin.Context, err error) {
-	c.JSON(500, gin.H{"error": err.Error()})
+	var validationErr *service.ValidationError
+
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(400, gin.H{"error": err.Error(), "problems": validationErr.Problems})
+	case errors.Is(err, service.ErrNotFound):
+		c.JSON(404, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrConflict):
+		c.JSON(409, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": err.Error()})
+	}
 }
@@ -0,0 +1,542 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"international_site/internal/storage/lts"
+	"international_site/internal/storage/models"
+	"international_site/internal/types"
+	"international_site/pkg/slug"
+	"international_site/pkg/units"
+	"strings"
+)
+
+func (i *Instance) CreateProduct(req types.ProductRequest, author string) (uint, error) {
+	product, err := i.productFromRequest(0, req)
+	if err != nil {
+		return 0, err
+	}
+
+	err = i.lts.Transaction(func(store lts.Protocol) error {
+		if err := store.CreateProduct(product); err != nil {
+			return err
+		}
+
+		return saveRevision(store, ItemProduct, product.ID, author)
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return product.ID, nil
+}
+
+func (i *Instance) UpdateProduct(id uint, req types.ProductRequest, author string) error {
+	product, err := i.productFromRequest(id, req)
+	if err != nil {
+		return err
+	}
+
+	product.ID = id
+
+	return i.tracked(ItemProduct, id, author, func(store lts.Protocol) error {
+		return store.UpdateProduct(product)
+	})
+}
+
+// DeleteProduct removes a product with its variants. Their last state is
+// stored as a revision first, so that RestoreRevision can bring them back.
+func (i *Instance) DeleteProduct(id uint, author string) error {
+	err := i.lts.Transaction(func(store lts.Protocol) error {
+		variants, err := store.GetVariantIDs(id)
+		if err != nil {
+			return err
+		}
+
+		for _, productID := range append([]uint{id}, variants...) {
+			if err := saveRevision(store, ItemProduct, productID, author); err != nil {
+				return err
+			}
+		}
+
+		return store.DeleteProduct(id)
+	})
+
+	if err == nil {
+		i.refreshVocabulary()
+	}
+
+	return err
+}
+
+func (i *Instance) productFromRequest(id uint, req types.ProductRequest) (*models.Product, error) {
+	languages, err := i.lts.GetLanguages()
+	if err != nil {
+		return nil, err
+	}
+
+	priceLists, err := i.lts.GetPriceLists()
+	if err != nil {
+		return nil, err
+	}
+
+	parent, variantValues, problems, err := i.variantValues(id, req)
+	if err != nil {
+		return nil, err
+	}
+
+	problems = append(validateProductRequest(req, languages, priceLists), problems...)
+
+	for idx, link := range req.Links {
+		if link.ProductID == id {
+			problems = append(problems, fmt.Sprintf("links[%d]: product cannot be linked to itself", idx))
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	product := &models.Product{
+		CategoryID:     req.CategoryID,
+		ParentID:       req.ParentID,
+		SKU:            strings.TrimSpace(req.SKU),
+		ImageURL:       req.ImageURL,
+		FileURL:        req.FileURL,
+		SortOrder:      req.SortOrder,
+		PriceOnRequest: req.PriceOnRequest,
+		LeadTimeDays:   req.LeadTimeDays,
+		VariantValues:  variantValues,
+	}
+
+	// variants always live in the category of their parent
+	if parent != nil {
+		product.CategoryID = parent.CategoryID
+	}
+
+	priceListIDs := make(map[string]uint, len(priceLists))
+	for _, priceList := range priceLists {
+		priceListIDs[priceList.Currency] = priceList.ID
+	}
+
+	for _, price := range req.Prices {
+		product.Prices = append(product.Prices, models.ProductPrice{
+			PriceListID: priceListIDs[strings.ToUpper(price.Currency)],
+			MinQuantity: max(price.MinQuantity, 1),
+			Amount:      price.Amount,
+		})
+	}
+
+	for _, media := range req.Media {
+		modelMedia := models.ProductMedia{
+			Kind:      media.Kind,
+			Type:      media.Type,
+			URL:       strings.TrimSpace(media.URL),
+			IsPrimary: media.IsPrimary,
+			SortOrder: media.SortOrder,
+		}
+
+		if media.LanguageCode != "" {
+			modelMedia.LanguageCode = &media.LanguageCode
+		}
+
+		for _, trans := range media.Translations {
+			modelMedia.Translations = append(modelMedia.Translations, models.ProductMediaTranslation{
+				LanguageCode: trans.LanguageCode,
+				Caption:      trans.Caption,
+			})
+		}
+
+		product.Media = append(product.Media, modelMedia)
+	}
+
+	for _, link := range req.Links {
+		product.Links = append(product.Links, models.ProductLink{
+			LinkedProductID: link.ProductID,
+			LinkType:        link.Type,
+			SortOrder:       link.SortOrder,
+		})
+	}
+
+	for _, trans := range req.Translations {
+		product.Translations = append(product.Translations, models.ProductTranslation{
+			LanguageCode: trans.LanguageCode,
+			Name:         trans.Name,
+			Description:  trans.Description,
+			ShortDesc:    trans.ShortDescription,
+		})
+	}
+
+	for _, spec := range req.Specs {
+		modelSpec := models.ProductSpec{SortOrder: spec.SortOrder}
+
+		if value, unit, ok := specNumericValue(spec); ok {
+			modelSpec.NumericValue = value
+			modelSpec.Unit = unit.Symbol
+			modelSpec.Dimension = string(unit.Dimension)
+		}
+
+		for _, trans := range spec.Translations {
+			modelSpec.Translations = append(modelSpec.Translations, models.ProductSpecTranslation{
+				LanguageCode: trans.LanguageCode,
+				Name:         trans.Name,
+				Value:        trans.Value,
+			})
+		}
+
+		product.Specs = append(product.Specs, modelSpec)
+	}
+
+	return product, nil
+}
+
+// validateProductRequest checks that the product and every spec is translated
+// into each language from the languages table, and into nothing else, and that
+// prices reference existing price lists without repeating a tier.
+func validateProductRequest(req types.ProductRequest, languages []models.Language, priceLists []models.PriceList) []string {
+	var problems []string
+
+	if strings.TrimSpace(req.SKU) == "" {
+		problems = append(problems, "sku must not be empty")
+	}
+
+	codes := make([]string, 0, len(req.Translations))
+	for _, trans := range req.Translations {
+		codes = append(codes, trans.LanguageCode)
+	}
+
+	problems = append(problems, checkLanguageCoverage("translations", codes, languages)...)
+
+	for idx, spec := range req.Specs {
+		codes = codes[:0]
+		for _, trans := range spec.Translations {
+			codes = append(codes, trans.LanguageCode)
+		}
+
+		problems = append(problems, checkLanguageCoverage(fmt.Sprintf("specs[%d].translations", idx), codes, languages)...)
+
+		if _, ok := units.Lookup(spec.Unit); spec.NumericValue != nil && !ok {
+			problems = append(problems, fmt.Sprintf("specs[%d].unit: unknown unit %q", idx, spec.Unit))
+		}
+	}
+
+	currencies := make(map[string]bool, len(priceLists))
+	for _, priceList := range priceLists {
+		currencies[priceList.Currency] = true
+	}
+
+	tiers := make(map[string]bool, len(req.Prices))
+	for idx, price := range req.Prices {
+		currency := strings.ToUpper(price.Currency)
+		tier := fmt.Sprintf("%s/%d", currency, max(price.MinQuantity, 1))
+
+		switch {
+		case !currencies[currency]:
+			problems = append(problems, fmt.Sprintf("prices[%d]: no price list in currency %q", idx, price.Currency))
+		case tiers[tier]:
+			problems = append(problems, fmt.Sprintf("prices[%d]: duplicate tier for %s from %d pcs", idx, currency, max(price.MinQuantity, 1)))
+		}
+
+		tiers[tier] = true
+	}
+
+	linked := make(map[uint]bool, len(req.Links))
+	for idx, link := range req.Links {
+		if linked[link.ProductID] {
+			problems = append(problems, fmt.Sprintf("links[%d]: product %d is linked twice", idx, link.ProductID))
+		}
+
+		linked[link.ProductID] = true
+	}
+
+	return append(problems, validateProductMedia(req.Media, languages)...)
+}
+
+// validateProductMedia allows at most one primary image per language scope;
+// captions are optional but must use known languages.
+func validateProductMedia(media []types.ProductMediaRequest, languages []models.Language) []string {
+	var problems []string
+
+	known := make(map[string]bool, len(languages))
+	for _, lang := range languages {
+		known[lang.Code] = true
+	}
+
+	primaries := make(map[string]bool, len(languages)+1)
+
+	for idx, item := range media {
+		if item.LanguageCode != "" && !known[item.LanguageCode] {
+			problems = append(problems, fmt.Sprintf("media[%d]: unknown language %q", idx, item.LanguageCode))
+		}
+
+		if item.IsPrimary {
+			switch {
+			case item.Kind != models.MediaImage:
+				problems = append(problems, fmt.Sprintf("media[%d]: only an image can be primary", idx))
+			case primaries[item.LanguageCode]:
+				problems = append(problems, fmt.Sprintf("media[%d]: more than one primary image for language %q", idx, item.LanguageCode))
+			}
+
+			primaries[item.LanguageCode] = true
+		}
+
+		seen := make(map[string]bool, len(item.Translations))
+		for _, trans := range item.Translations {
+			switch {
+			case !known[trans.LanguageCode]:
+				problems = append(problems, fmt.Sprintf("media[%d].translations: unknown language %q", idx, trans.LanguageCode))
+			case seen[trans.LanguageCode]:
+				problems = append(problems, fmt.Sprintf("media[%d].translations: duplicate language %q", idx, trans.LanguageCode))
+			}
+
+			seen[trans.LanguageCode] = true
+		}
+	}
+
+	return problems
+}
+
+func checkLanguageCoverage(field string, codes []string, languages []models.Language) []string {
+	var problems []string
+
+	known := make(map[string]bool, len(languages))
+	for _, lang := range languages {
+		known[lang.Code] = true
+	}
+
+	seen := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		switch {
+		case !known[code]:
+			problems = append(problems, fmt.Sprintf("%s: unknown language %q", field, code))
+		case seen[code]:
+			problems = append(problems, fmt.Sprintf("%s: duplicate language %q", field, code))
+		}
+
+		seen[code] = true
+	}
+
+	for _, lang := range languages {
+		if !seen[lang.Code] {
+			problems = append(problems, fmt.Sprintf("%s: missing language %q", field, lang.Code))
+		}
+	}
+
+	return problems
+}
+
+func (i *Instance) CreateCategory(req types.CategoryRequest) (uint, error) {
+	category, err := i.categoryFromRequest(0, req)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := i.lts.CreateCategory(category); err != nil {
+		return 0, err
+	}
+
+	return category.ID, nil
+}
+
+func (i *Instance) UpdateCategory(id uint, req types.CategoryRequest) error {
+	category, err := i.categoryFromRequest(id, req)
+	if err != nil {
+		return err
+	}
+
+	category.ID = id
+
+	return i.lts.UpdateCategory(category)
+}
+
+func (i *Instance) DeleteCategory(id uint) error {
+	return i.lts.DeleteCategory(id)
+}
+
+func (i *Instance) categoryFromRequest(id uint, req types.CategoryRequest) (*models.ProductCategory, error) {
+	languages, err := i.lts.GetLanguages()
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, len(req.Translations))
+	for _, trans := range req.Translations {
+		codes = append(codes, trans.LanguageCode)
+	}
+
+	problems := checkLanguageCoverage("translations", codes, languages)
+
+	for _, trans := range req.Translations {
+		if trans.Slug != "" && !slug.Valid(trans.Slug) {
+			problems = append(problems, fmt.Sprintf("translations: slug %q for %q may contain only a-z, 0-9 and single dashes", trans.Slug, trans.LanguageCode))
+		}
+	}
+
+	if req.ParentID != nil {
+		cyclic, err := i.isCategoryDescendant(*req.ParentID, id)
+
+		switch {
+		case errors.Is(err, ErrNotFound):
+			problems = append(problems, fmt.Sprintf("parent_id: category %d does not exist", *req.ParentID))
+		case err != nil:
+			return nil, err
+		case cyclic:
+			problems = append(problems, "parent_id: category cannot be moved under itself")
+		}
+	}
+
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	category := &models.ProductCategory{
+		ParentID:  req.ParentID,
+		SortOrder: req.SortOrder,
+	}
+
+	for _, trans := range req.Translations {
+		categorySlug := trans.Slug
+		if categorySlug == "" {
+			categorySlug, err = i.uniqueCategorySlug(trans.Name, trans.LanguageCode, id)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		category.Translations = append(category.Translations, models.ProductCategoryTranslation{
+			LanguageCode: trans.LanguageCode,
+			Name:         trans.Name,
+			Description:  trans.Description,
+			Slug:         categorySlug,
+		})
+	}
+
+	return category, nil
+}
+
+// uniqueCategorySlug transliterates name and appends -2, -3, ... until the
+// slug is free within the language.
+func (i *Instance) uniqueCategorySlug(name, locale string, categoryID uint) (string, error) {
+	base := slug.Make(name)
+	if base == "" {
+		base = "category"
+	}
+
+	candidate := base
+
+	for n := 2; ; n++ {
+		taken, err := i.lts.CategorySlugExists(candidate, locale, categoryID)
+		if err != nil {
+			return "", err
+		}
+
+		if !taken {
+			return candidate, nil
+		}
+
+		candidate = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// isCategoryDescendant walks up from candidateID and reports whether it reaches ancestorID.
+func (i *Instance) isCategoryDescendant(candidateID, ancestorID uint) (bool, error) {
+	for current := &candidateID; current != nil; {
+		if *current == ancestorID {
+			return true, nil
+		}
+
+		category, err := i.lts.GetCategoryByID(*current, i.cfg.DefaultLang)
+		if err != nil {
+			return false, err
+		}
+
+		current = category.ParentID
+	}
+
+	return false, nil
+}
+
+// UpdatePage replaces the slug, template and translations of a page and
+// records a revision. The status is changed separately with SetStatus.
+func (i *Instance) UpdatePage(id uint, req types.PageRequest, author string) error {
+	languages, err := i.lts.GetLanguages()
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+
+	if !slug.Valid(req.Slug) {
+		problems = append(problems, fmt.Sprintf("slug: %q may contain only a-z, 0-9 and single dashes", req.Slug))
+	}
+
+	codes := make([]string, 0, len(req.Translations))
+	page := &models.Page{ID: id, Slug: req.Slug, Template: req.Template}
+
+	for _, trans := range req.Translations {
+		if trans.Content != "" && !json.Valid([]byte(trans.Content)) {
+			problems = append(problems, fmt.Sprintf("translations[%s].content: must be a JSON document", trans.LanguageCode))
+		}
+
+		codes = append(codes, trans.LanguageCode)
+		page.Translations = append(page.Translations, models.PageTranslation{
+			LanguageCode: trans.LanguageCode,
+			Title:        trans.Title,
+			Content:      trans.Content,
+			MetaTitle:    trans.MetaTitle,
+			MetaDesc:     trans.MetaDescription,
+		})
+	}
+
+	problems = append(problems, checkLanguageCoverage("translations", codes, languages)...)
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+
+	return i.tracked(ItemPage, id, author, func(store lts.Protocol) error {
+		return store.UpdatePage(page)
+	})
+}
+
+// UpdateNews replaces the image and translations of a news item and records
+// a revision. The publication window is kept; SetNewsSchedule changes it.
+func (i *Instance) UpdateNews(id uint, req types.NewsRequest, author string) error {
+	languages, err := i.lts.GetLanguages()
+	if err != nil {
+		return err
+	}
+
+	current, err := i.lts.GetNewsSnapshot(id)
+	if err != nil {
+		return err
+	}
+
+	codes := make([]string, 0, len(req.Translations))
+	news := &models.News{
+		ID:          id,
+		ImageURL:    req.ImageURL,
+		PublishAt:   current.PublishAt,
+		UnpublishAt: current.UnpublishAt,
+	}
+
+	for _, trans := range req.Translations {
+		codes = append(codes, trans.LanguageCode)
+		news.Translations = append(news.Translations, models.NewsTranslation{
+			LanguageCode: trans.LanguageCode,
+			Title:        trans.Title,
+			Content:      trans.Content,
+			Excerpt:      trans.Excerpt,
+		})
+	}
+
+	if problems := checkLanguageCoverage("translations", codes, languages); len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+
+	return i.tracked(ItemNews, id, author, func(store lts.Protocol) error {
+		return store.UpdateNews(news)
+	})
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"fmt"
+	"international_site/internal/storage/models"
+	"international_site/internal/types"
+)
+
+// breadcrumbLabels names the fixed sections; unknown locales use English.
+var breadcrumbLabels = map[string]map[string]string{
+	"ru": {"home": "Главная", "products": "Продукция", "news": "Новости"},
+	"en": {"home": "Home", "products": "Products", "news": "News"},
+	"pl": {"home": "Strona główna", "products": "Produkty", "news": "Aktualności"},
+}
+
+func breadcrumbLabel(locale, key string) string {
+	if labels, ok := breadcrumbLabels[locale]; ok {
+		return labels[key]
+	}
+
+	return breadcrumbLabels["en"][key]
+}
+
+// GetBreadcrumbs returns the trail from the home page down to the given item.
+func (i *Instance) GetBreadcrumbs(locale, itemType string, id uint) ([]types.Breadcrumb, error) {
+	switch itemType {
+	case ItemProduct:
+		product, err := i.GetProductByID(locale, id)
+		if err != nil {
+			return nil, err
+		}
+
+		return i.productBreadcrumbs(locale, product)
+
+	case ItemCategory:
+		return i.categoryBreadcrumbs(locale, id)
+
+	case ItemNews:
+		news, err := i.GetNewsByID(locale, id, "")
+		if err != nil {
+			return nil, err
+		}
+
+		title := ""
+		if len(news.Translations) > 0 {
+			title = news.Translations[0].Title
+		}
+
+		return append(i.rootBreadcrumbs(locale, "news"), types.Breadcrumb{
+			Type: ItemNews,
+			ID:   news.ID,
+			Name: title,
+			URL:  i.localeURL(locale, fmt.Sprintf("news/%d", news.ID)),
+		}), nil
+
+	case ItemPage:
+		page, err := i.lts.GetPageByID(id, locale)
+		if err != nil {
+			return nil, err
+		}
+
+		if page.Status != models.StatusPublished {
+			return nil, ErrNotFound
+		}
+
+		title := page.Slug
+		if len(page.Translations) > 0 && page.Translations[0].Title != "" {
+			title = page.Translations[0].Title
+		}
+
+		return append(i.rootBreadcrumbs(locale), types.Breadcrumb{
+			Type: ItemPage,
+			ID:   page.ID,
+			Name: title,
+			URL:  i.localeURL(locale, "page/"+page.Slug),
+		}), nil
+	}
+
+	return nil, &ValidationError{Problems: []string{
+		fmt.Sprintf("type: %q must be one of product, category, news, page", itemType),
+	}}
+}
+
+// GetProductDetail returns the product page data together with its breadcrumbs.
+// A valid preview token also shows the product while it is unpublished.
+func (i *Instance) GetProductDetail(locale string, id uint, preview string) (*types.ProductDetail, error) {
+	product, err := i.getProduct(locale, id, preview)
+	if err != nil {
+		return nil, err
+	}
+
+	breadcrumbs, err := i.productBreadcrumbs(locale, product)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.ProductDetail{Product: product, Breadcrumbs: breadcrumbs}, nil
+}
+
+// productBreadcrumbs walks the category chain of the product; a variant also
+// gets its parent product before itself.
+func (i *Instance) productBreadcrumbs(locale string, product *models.Product) ([]types.Breadcrumb, error) {
+	breadcrumbs, err := i.categoryBreadcrumbs(locale, product.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if product.ParentID != nil {
+		parent, err := i.lts.GetProductByID(*product.ParentID, locale)
+		if err != nil {
+			return nil, err
+		}
+
+		breadcrumbs = append(breadcrumbs, i.productBreadcrumb(locale, parent))
+	}
+
+	return append(breadcrumbs, i.productBreadcrumb(locale, product)), nil
+}
+
+func (i *Instance) categoryBreadcrumbs(locale string, categoryID uint) ([]types.Breadcrumb, error) {
+	path, err := i.lts.GetCategoryAncestors(categoryID, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	breadcrumbs := i.rootBreadcrumbs(locale, "products")
+
+	for _, category := range path {
+		breadcrumbs = append(breadcrumbs, types.Breadcrumb{
+			Type: ItemCategory,
+			ID:   category.ID,
+			Name: category.Name,
+			URL:  i.localeURL(locale, "products/"+category.Slug),
+		})
+	}
+
+	return breadcrumbs, nil
+}
+
+func (i *Instance) productBreadcrumb(locale string, product *models.Product) types.Breadcrumb {
+	name := product.SKU
+	if len(product.Translations) > 0 && product.Translations[0].Name != "" {
+		name = product.Translations[0].Name
+	}
+
+	return types.Breadcrumb{
+		Type: ItemProduct,
+		ID:   product.ID,
+		Name: name,
+		URL:  i.localeURL(locale, fmt.Sprintf("product/%d", product.ID)),
+	}
+}
+
+// rootBreadcrumbs starts every trail with the home page and the given sections.
+func (i *Instance) rootBreadcrumbs(locale string, sections ...string) []types.Breadcrumb {
+	breadcrumbs := []types.Breadcrumb{{
+		Type: "home",
+		Name: breadcrumbLabel(locale, "home"),
+		URL:  i.localeURL(locale, ""),
+	}}
+
+	for _, section := range sections {
+		breadcrumbs = append(breadcrumbs, types.Breadcrumb{
+			Type: section,
+			Name: breadcrumbLabel(locale, section),
+			URL:  i.localeURL(locale, section),
+		})
+	}
+
+	return breadcrumbs
+}
+
+// localeURL builds a canonical URL the same way the sitemap does.
+func (i *Instance) localeURL(locale, path string) string {
+	return fmt.Sprintf("%s/%s/%s", i.cfg.SiteURL, locale, path)
+}
@@ -0,0 +1,615 @@
+package service
+
+import (
+	"fmt"
+	"international_site/internal/storage/lts"
+	"international_site/internal/storage/models"
+	"international_site/internal/types"
+	"strconv"
+	"strings"
+)
+
+// Catalog spreadsheet layout: one row per SKU, fixed columns followed by
+// name_<lang>, short_description_<lang>, description_<lang> and specs_<lang>
+// for every language. A specs cell lists "Name: Value" pairs separated by
+// semicolons or line breaks; specs of different languages are matched by position.
+const (
+	colSKU            = "sku"
+	colParentSKU      = "parent_sku"
+	colCategoryID     = "category_id"
+	colImageURL       = "image_url"
+	colFileURL        = "file_url"
+	colSortOrder      = "sort_order"
+	colPriceOnRequest = "price_on_request"
+	colLeadTimeDays   = "lead_time_days"
+
+	colName             = "name_"
+	colShortDescription = "short_description_"
+	colDescription      = "description_"
+	colSpecs            = "specs_"
+)
+
+// Import row actions
+const (
+	importCreate    = "create"
+	importUpdate    = "update"
+	importUnchanged = "unchanged"
+	importError     = "error"
+)
+
+var catalogFixedColumns = []string{
+	colSKU, colParentSKU, colCategoryID, colImageURL, colFileURL, colSortOrder, colPriceOnRequest, colLeadTimeDays,
+}
+
+var catalogLanguageColumns = []string{colName, colShortDescription, colDescription, colSpecs}
+
+// ExportCatalog returns the header and one row per product, in the layout
+// ImportCatalog reads.
+func (i *Instance) ExportCatalog() ([][]string, error) {
+	languages, err := i.lts.GetLanguages()
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := i.lts.GetCatalog()
+	if err != nil {
+		return nil, err
+	}
+
+	skus := make(map[uint]string, len(products))
+	for _, product := range products {
+		skus[product.ID] = product.SKU
+	}
+
+	rows := [][]string{catalogHeader(languages)}
+	for idx := range products {
+		rows = append(rows, catalogRow(&products[idx], skus, languages))
+	}
+
+	return rows, nil
+}
+
+// ImportCatalog validates rows and upserts products by SKU. Nothing is written
+// when dryRun is set or when any row fails validation. Columns missing from
+// the file keep their current values on existing products; parent_sku is
+// informational and only the admin API creates variants. Every product
+// written gets a revision by author.
+func (i *Instance) ImportCatalog(rows [][]string, dryRun bool, author string) (*types.ImportReport, error) {
+	report := &types.ImportReport{DryRun: dryRun}
+
+	languages, err := i.lts.GetLanguages()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		report.Errors = append(report.Errors, "file is empty")
+		return report, nil
+	}
+
+	columns, problems := catalogColumns(rows[0], languages)
+	if len(problems) > 0 {
+		report.Errors = problems
+		return report, nil
+	}
+
+	categories, err := i.lts.GetCategoryTree(i.cfg.DefaultLang)
+	if err != nil {
+		return nil, err
+	}
+
+	categoryIDs := make(map[uint]bool, len(categories))
+	for _, category := range categories {
+		categoryIDs[category.ID] = true
+	}
+
+	existing, err := i.lts.GetCatalog()
+	if err != nil {
+		return nil, err
+	}
+
+	bySKU := make(map[string]*models.Product, len(existing))
+	skus := make(map[uint]string, len(existing))
+	for idx := range existing {
+		bySKU[strings.ToLower(existing[idx].SKU)] = &existing[idx]
+		skus[existing[idx].ID] = existing[idx].SKU
+	}
+
+	var changed []models.Product
+
+	seen := make(map[string]int, len(rows))
+
+	for idx, cells := range rows[1:] {
+		if isBlankRow(cells) {
+			continue
+		}
+
+		row := catalogCells{columns: columns, cells: cells}
+		result := types.ImportRowResult{Row: idx + 2, SKU: row.get(colSKU)}
+
+		if first, ok := seen[strings.ToLower(result.SKU)]; ok && result.SKU != "" {
+			result.Errors = append(result.Errors, fmt.Sprintf("sku %q already appears in row %d", result.SKU, first))
+		} else {
+			seen[strings.ToLower(result.SKU)] = result.Row
+		}
+
+		current := bySKU[strings.ToLower(result.SKU)]
+
+		product, rowProblems := mergeCatalogRow(current, row, languages, categoryIDs)
+		result.Errors = append(result.Errors, rowProblems...)
+
+		switch {
+		case len(result.Errors) > 0:
+			result.Action = importError
+		case current == nil:
+			result.Action = importCreate
+			report.Created++
+			changed = append(changed, *product)
+		default:
+			result.Changes = catalogChanges(current, product, skus, languages)
+
+			if len(result.Changes) == 0 {
+				result.Action = importUnchanged
+				report.Unchanged++
+				break
+			}
+
+			result.Action = importUpdate
+			report.Updated++
+			changed = append(changed, *product)
+		}
+
+		report.Rows = append(report.Rows, result)
+	}
+
+	if dryRun || report.HasErrors() || len(changed) == 0 {
+		return report, nil
+	}
+
+	err = i.lts.Transaction(func(store lts.Protocol) error {
+		for idx := range changed {
+			if changed[idx].ID == 0 {
+				continue
+			}
+
+			if err := saveBaseline(store, ItemProduct, changed[idx].ID); err != nil {
+				return err
+			}
+		}
+
+		if err := store.ImportProducts(changed); err != nil {
+			return err
+		}
+
+		for idx := range changed {
+			if err := saveRevision(store, ItemProduct, changed[idx].ID, author); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	report.Applied = true
+
+	i.refreshVocabulary()
+
+	return report, nil
+}
+
+// catalogColumns maps column names to their index and checks that the header
+// holds sku, category_id and a name for every language, and nothing unknown.
+func catalogColumns(header []string, languages []models.Language) (map[string]int, []string) {
+	var problems []string
+
+	known := make(map[string]bool)
+	for _, column := range catalogFixedColumns {
+		known[column] = true
+	}
+
+	for _, lang := range languages {
+		for _, prefix := range catalogLanguageColumns {
+			known[prefix+lang.Code] = true
+		}
+	}
+
+	columns := make(map[string]int, len(header))
+
+	for idx, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		switch {
+		case name == "":
+			continue
+		case !known[name]:
+			problems = append(problems, fmt.Sprintf("unknown column %q", name))
+		case hasColumn(columns, name):
+			problems = append(problems, fmt.Sprintf("duplicate column %q", name))
+		}
+
+		columns[name] = idx
+	}
+
+	required := []string{colSKU, colCategoryID}
+	for _, lang := range languages {
+		required = append(required, colName+lang.Code)
+	}
+
+	for _, name := range required {
+		if !hasColumn(columns, name) {
+			problems = append(problems, fmt.Sprintf("missing column %q", name))
+		}
+	}
+
+	specColumns := 0
+	for _, lang := range languages {
+		if hasColumn(columns, colSpecs+lang.Code) {
+			specColumns++
+		}
+	}
+
+	if specColumns > 0 && specColumns < len(languages) {
+		problems = append(problems, "specs columns must be present for every language or for none")
+	}
+
+	return columns, problems
+}
+
+func hasColumn(columns map[string]int, name string) bool {
+	_, ok := columns[name]
+	return ok
+}
+
+func isBlankRow(cells []string) bool {
+	for _, cell := range cells {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+type catalogCells struct {
+	columns map[string]int
+	cells   []string
+}
+
+func (r catalogCells) has(column string) bool {
+	return hasColumn(r.columns, column)
+}
+
+func (r catalogCells) get(column string) string {
+	idx, ok := r.columns[column]
+	if !ok || idx >= len(r.cells) {
+		return ""
+	}
+
+	return strings.TrimSpace(r.cells[idx])
+}
+
+// mergeCatalogRow applies the columns present in row on top of current, or on
+// top of an empty product when the SKU is new.
+func mergeCatalogRow(current *models.Product, row catalogCells, languages []models.Language, categoryIDs map[uint]bool) (*models.Product, []string) {
+	var problems []string
+
+	product := &models.Product{}
+	if current != nil {
+		*product = *current
+		product.Translations = append([]models.ProductTranslation(nil), current.Translations...)
+	} else if row.get(colParentSKU) != "" {
+		problems = append(problems, "new variants cannot be imported; create them through the admin API")
+	}
+
+	product.SKU = row.get(colSKU)
+	if product.SKU == "" {
+		problems = append(problems, "sku must not be empty")
+	}
+
+	categoryID, err := strconv.Atoi(row.get(colCategoryID))
+	switch {
+	case err != nil || categoryID <= 0:
+		problems = append(problems, fmt.Sprintf("category_id: %q is not a category id", row.get(colCategoryID)))
+	case !categoryIDs[uint(categoryID)]:
+		problems = append(problems, fmt.Sprintf("category_id: category %d does not exist", categoryID))
+	default:
+		product.CategoryID = uint(categoryID)
+	}
+
+	if row.has(colImageURL) {
+		product.ImageURL = row.get(colImageURL)
+	}
+
+	if row.has(colFileURL) {
+		product.FileURL = row.get(colFileURL)
+	}
+
+	intColumns := []struct {
+		name string
+		dst  *int
+	}{
+		{colSortOrder, &product.SortOrder},
+		{colLeadTimeDays, &product.LeadTimeDays},
+	}
+
+	for _, column := range intColumns {
+		if !row.has(column.name) {
+			continue
+		}
+
+		value, err := atoiOrZero(row.get(column.name))
+		if err != nil || value < 0 {
+			problems = append(problems, fmt.Sprintf("%s: %q is not a non-negative integer", column.name, row.get(column.name)))
+			continue
+		}
+
+		*column.dst = value
+	}
+
+	if row.has(colPriceOnRequest) {
+		value, err := parseCatalogBool(row.get(colPriceOnRequest))
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("price_on_request: %q is not a boolean", row.get(colPriceOnRequest)))
+		}
+
+		product.PriceOnRequest = value
+	}
+
+	for _, lang := range languages {
+		trans := findTranslation(product, lang.Code)
+
+		trans.Name = row.get(colName + lang.Code)
+		if trans.Name == "" {
+			problems = append(problems, fmt.Sprintf("%s%s must not be empty", colName, lang.Code))
+		}
+
+		if row.has(colShortDescription + lang.Code) {
+			trans.ShortDesc = row.get(colShortDescription + lang.Code)
+		}
+
+		if row.has(colDescription + lang.Code) {
+			trans.Description = row.get(colDescription + lang.Code)
+		}
+	}
+
+	if len(languages) > 0 && row.has(colSpecs+languages[0].Code) {
+		specs, specProblems := catalogSpecs(current, row, languages)
+		problems = append(problems, specProblems...)
+		product.Specs = specs
+	}
+
+	return product, problems
+}
+
+// findTranslation returns the product translation for code, adding an empty one when missing.
+func findTranslation(product *models.Product, code string) *models.ProductTranslation {
+	for idx := range product.Translations {
+		if product.Translations[idx].LanguageCode == code {
+			return &product.Translations[idx]
+		}
+	}
+
+	product.Translations = append(product.Translations, models.ProductTranslation{ProductID: product.ID, LanguageCode: code})
+
+	return &product.Translations[len(product.Translations)-1]
+}
+
+// catalogSpecs parses the specs_<lang> cells. A spec whose translations did not
+// change keeps its stored numeric value; others are parsed from the text again.
+func catalogSpecs(current *models.Product, row catalogCells, languages []models.Language) ([]models.ProductSpec, []string) {
+	parsed := make(map[string][][2]string, len(languages))
+	count := -1
+
+	for _, lang := range languages {
+		pairs, err := parseSpecCell(row.get(colSpecs + lang.Code))
+		if err != nil {
+			return nil, []string{fmt.Sprintf("%s%s: %v", colSpecs, lang.Code, err)}
+		}
+
+		if count >= 0 && len(pairs) != count {
+			return nil, []string{fmt.Sprintf("%s%s has %d specs, %s%s has %d",
+				colSpecs, lang.Code, len(pairs), colSpecs, languages[0].Code, count)}
+		}
+
+		parsed[lang.Code] = pairs
+		count = len(pairs)
+	}
+
+	specs := make([]models.ProductSpec, 0, count)
+
+	for pos := 0; pos < count; pos++ {
+		req := types.ProductSpecRequest{SortOrder: pos + 1}
+		for _, lang := range languages {
+			pair := parsed[lang.Code][pos]
+			req.Translations = append(req.Translations, types.SpecTranslationRequest{
+				LanguageCode: lang.Code,
+				Name:         pair[0],
+				Value:        pair[1],
+			})
+		}
+
+		if current != nil && pos < len(current.Specs) && sameSpecTranslations(current.Specs[pos], req) {
+			spec := current.Specs[pos]
+			spec.SortOrder = pos + 1
+			specs = append(specs, spec)
+
+			continue
+		}
+
+		spec := models.ProductSpec{SortOrder: pos + 1}
+
+		if value, unit, ok := specNumericValue(req); ok {
+			spec.NumericValue = value
+			spec.Unit = unit.Symbol
+			spec.Dimension = string(unit.Dimension)
+		}
+
+		for _, trans := range req.Translations {
+			spec.Translations = append(spec.Translations, models.ProductSpecTranslation{
+				LanguageCode: trans.LanguageCode,
+				Name:         trans.Name,
+				Value:        trans.Value,
+			})
+		}
+
+		specs = append(specs, spec)
+	}
+
+	return specs, nil
+}
+
+func sameSpecTranslations(spec models.ProductSpec, req types.ProductSpecRequest) bool {
+	if len(spec.Translations) != len(req.Translations) {
+		return false
+	}
+
+	for _, want := range req.Translations {
+		found := false
+
+		for _, trans := range spec.Translations {
+			if trans.LanguageCode == want.LanguageCode && trans.Name == want.Name && trans.Value == want.Value {
+				found = true
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseSpecCell splits "Name: Value; Name: Value" into name/value pairs.
+func parseSpecCell(cell string) ([][2]string, error) {
+	var pairs [][2]string
+
+	entries := strings.FieldsFunc(cell, func(r rune) bool { return r == ';' || r == '\n' })
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(entry, ":")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("%q is not in the form \"Name: Value\"", entry)
+		}
+
+		pairs = append(pairs, [2]string{name, value})
+	}
+
+	return pairs, nil
+}
+
+func formatSpecCell(specs []models.ProductSpec, code string) string {
+	entries := make([]string, 0, len(specs))
+
+	for _, spec := range specs {
+		for _, trans := range spec.Translations {
+			if trans.LanguageCode == code {
+				entries = append(entries, trans.Name+": "+trans.Value)
+			}
+		}
+	}
+
+	return strings.Join(entries, "; ")
+}
+
+func atoiOrZero(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	return strconv.Atoi(s)
+}
+
+func parseCatalogBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "", "no", "нет", "nie":
+		return false, nil
+	case "yes", "да", "tak":
+		return true, nil
+	}
+
+	return strconv.ParseBool(s)
+}
+
+func catalogHeader(languages []models.Language) []string {
+	header := append([]string(nil), catalogFixedColumns...)
+
+	for _, prefix := range catalogLanguageColumns {
+		for _, lang := range languages {
+			header = append(header, prefix+lang.Code)
+		}
+	}
+
+	return header
+}
+
+// catalogRow formats product in catalogHeader order; skus resolves the parent SKU of variants.
+func catalogRow(product *models.Product, skus map[uint]string, languages []models.Language) []string {
+	var parentSKU string
+	if product.ParentID != nil {
+		parentSKU = skus[*product.ParentID]
+	}
+
+	row := []string{
+		product.SKU,
+		parentSKU,
+		strconv.Itoa(int(product.CategoryID)),
+		product.ImageURL,
+		product.FileURL,
+		strconv.Itoa(product.SortOrder),
+		strconv.FormatBool(product.PriceOnRequest),
+		strconv.Itoa(product.LeadTimeDays),
+	}
+
+	translations := make(map[string]models.ProductTranslation, len(product.Translations))
+	for _, trans := range product.Translations {
+		translations[trans.LanguageCode] = trans
+	}
+
+	for _, prefix := range catalogLanguageColumns {
+		for _, lang := range languages {
+			trans := translations[lang.Code]
+
+			switch prefix {
+			case colName:
+				row = append(row, trans.Name)
+			case colShortDescription:
+				row = append(row, trans.ShortDesc)
+			case colDescription:
+				row = append(row, trans.Description)
+			case colSpecs:
+				row = append(row, formatSpecCell(product.Specs, lang.Code))
+			}
+		}
+	}
+
+	return row
+}
+
+// catalogChanges lists the columns whose exported value differs between current and updated.
+func catalogChanges(current, updated *models.Product, skus map[uint]string, languages []models.Language) []types.FieldChange {
+	var changes []types.FieldChange
+
+	header := catalogHeader(languages)
+	before := catalogRow(current, skus, languages)
+	after := catalogRow(updated, skus, languages)
+
+	for idx := range header {
+		if before[idx] != after[idx] {
+			changes = append(changes, types.FieldChange{Column: header[idx], Old: before[idx], New: after[idx]})
+		}
+	}
+
+	return changes
+}
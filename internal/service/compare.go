@@ -0,0 +1,130 @@
+package service
+
+import (
+	"fmt"
+	"international_site/internal/storage/models"
+	"international_site/internal/types"
+	"math"
+)
+
+const (
+	minCompareProducts = 2
+	maxCompareProducts = 10
+)
+
+// CompareProducts builds a comparison table for the products in ids order.
+// Rows follow the spec order of the first product that has the spec.
+func (i *Instance) CompareProducts(locale string, ids []uint) (*types.ProductComparison, error) {
+	var problems []string
+
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			problems = append(problems, fmt.Sprintf("ids: product %d is listed twice", id))
+		}
+
+		seen[id] = true
+	}
+
+	if len(ids) < minCompareProducts || len(ids) > maxCompareProducts {
+		problems = append(problems, fmt.Sprintf("ids: between %d and %d products can be compared, got %d", minCompareProducts, maxCompareProducts, len(ids)))
+	}
+
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	products, err := i.lts.GetProductsByIDs(ids, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	i.prepareProducts(locale, products)
+
+	byID := make(map[uint]*models.Product, len(products))
+	for idx := range products {
+		byID[products[idx].ID] = &products[idx]
+	}
+
+	comparison := &types.ProductComparison{Products: make([]types.ComparedProduct, 0, len(ids))}
+	rowIndex := make(map[string]int)
+
+	for col, id := range ids {
+		product, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: product %d", ErrNotFound, id)
+		}
+
+		comparison.Products = append(comparison.Products, comparedProduct(product))
+
+		for _, spec := range product.Specs {
+			if len(spec.Translations) == 0 {
+				continue
+			}
+
+			name := spec.Translations[0].Name
+
+			row, ok := rowIndex[name]
+			if !ok {
+				row = len(comparison.Rows)
+				rowIndex[name] = row
+				comparison.Rows = append(comparison.Rows, types.ComparisonRow{
+					Name:   name,
+					Values: make([]*types.ComparisonValue, len(ids)),
+				})
+			}
+
+			comparison.Rows[row].Values[col] = &types.ComparisonValue{
+				Value:        spec.Translations[0].Value,
+				DisplayValue: spec.DisplayValue,
+				DisplayUnit:  spec.DisplayUnit,
+			}
+		}
+	}
+
+	for idx := range comparison.Rows {
+		comparison.Rows[idx].Differs = valuesDiffer(comparison.Rows[idx].Values)
+	}
+
+	return comparison, nil
+}
+
+func comparedProduct(product *models.Product) types.ComparedProduct {
+	compared := types.ComparedProduct{
+		ID:           product.ID,
+		SKU:          product.SKU,
+		ImageURL:     product.PrimaryImageURL,
+		Availability: product.Availability,
+	}
+
+	if len(product.Translations) > 0 {
+		compared.Name = product.Translations[0].Name
+	}
+
+	if len(product.Category.Translations) > 0 {
+		compared.CategoryName = product.Category.Translations[0].Name
+	}
+
+	return compared
+}
+
+// valuesDiffer compares numeric values when both sides have one and the
+// translated text otherwise; a missing value always differs.
+func valuesDiffer(values []*types.ComparisonValue) bool {
+	first := values[0]
+
+	for _, value := range values[1:] {
+		switch {
+		case first == nil || value == nil:
+			return true
+		case first.DisplayValue != nil && value.DisplayValue != nil:
+			if math.Abs(*first.DisplayValue-*value.DisplayValue) > 1e-9 {
+				return true
+			}
+		case first.Value != value.Value:
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"international_site/internal/storage/lts"
+	"international_site/internal/storage/models"
+	"international_site/internal/types"
+	"strings"
+)
+
+// Cursor pages walk the default listing order only; custom sorting keeps
+// using offsets.
+var errCursorSort = &ValidationError{Problems: []string{"cursor: only the default sorting supports cursor pagination"}}
+
+// decodeCursor turns the opaque token back into a list position and checks
+// the page size. An empty token is the first page.
+func decodeCursor(token string, limit int) (*lts.ListCursor, error) {
+	if limit <= 0 {
+		return nil, &ValidationError{Problems: []string{"limit: must be positive"}}
+	}
+
+	cursor := &lts.ListCursor{}
+	if token == "" {
+		return cursor, nil
+	}
+
+	if err := decodeToken(token, cursor); err != nil || cursor.ID == 0 {
+		return nil, &ValidationError{Problems: []string{fmt.Sprintf("cursor: %q is not a valid cursor", token)}}
+	}
+
+	return cursor, nil
+}
+
+// decodeToken reads a cursor written by encodeCursor into cursor.
+func decodeToken(token string, cursor any) error {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, cursor)
+}
+
+// encodeCursor turns a list position into an opaque token.
+func encodeCursor(cursor any) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// FilterProductsAfter returns up to filter.Limit products after the cursor and
+// the cursor of the next page, empty on the last one.
+func (i *Instance) FilterProductsAfter(filter types.ProductFilter, cursor string) ([]models.Product, string, error) {
+	if (filter.SortBy != "" && filter.SortBy != "sort_order") || strings.EqualFold(filter.SortOrder, "desc") {
+		return nil, "", errCursorSort
+	}
+
+	after, err := decodeCursor(cursor, filter.Limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	resolved, err := i.storageFilter(filter)
+	if err != nil {
+		return nil, "", err
+	}
+
+	limit := filter.Limit
+	resolved.After, resolved.Limit = after, limit+1
+
+	products, _, err := i.lts.FilterProducts(resolved)
+	if err != nil {
+		return nil, "", err
+	}
+
+	products, next := nextProductCursor(products, limit)
+	i.prepareProducts(filter.Locale, products)
+
+	return products, next, nil
+}
+
+func (i *Instance) GetProductsAfter(locale, cursor string, limit int) ([]models.Product, string, error) {
+	return i.FilterProductsAfter(types.ProductFilter{Locale: locale, Limit: limit}, cursor)
+}
+
+func (i *Instance) GetProductsByCategoryAfter(locale string, categoryID uint, includeDescendants bool, cursor string, limit int) ([]models.Product, string, error) {
+	return i.FilterProductsAfter(types.ProductFilter{
+		Locale:             locale,
+		CategoryID:         categoryID,
+		IncludeDescendants: includeDescendants,
+		Limit:              limit,
+	}, cursor)
+}
+
+func (i *Instance) GetNewsAfter(locale, cursor string, limit int) ([]models.News, string, error) {
+	after, err := decodeCursor(cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	news, err := i.lts.GetNewsAfter(locale, i.NowFunc(), after, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(news) <= limit {
+		return news, "", nil
+	}
+
+	news = news[:limit]
+	last := news[limit-1]
+
+	return news, encodeCursor(lts.ListCursor{CreatedAt: newsDate(last), ID: last.ID}), nil
+}
+
+// nextProductCursor drops the extra row fetched to detect a next page.
+func nextProductCursor(products []models.Product, limit int) ([]models.Product, string) {
+	if len(products) <= limit {
+		return products, ""
+	}
+
+	products = products[:limit]
+	last := products[limit-1]
+
+	return products, encodeCursor(lts.ListCursor{SortOrder: last.SortOrder, CreatedAt: last.CreatedAt, ID: last.ID})
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"errors"
+	"international_site/internal/storage/lts"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	want := lts.ListCursor{SortOrder: 3, CreatedAt: time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC), ID: 42}
+
+	got, err := decodeCursor(encodeCursor(want), 10)
+	if err != nil {
+		t.Fatalf("decodeCursor: %v", err)
+	}
+
+	if got.SortOrder != want.SortOrder || !got.CreatedAt.Equal(want.CreatedAt) || got.ID != want.ID {
+		t.Errorf("decodeCursor(encodeCursor(%+v)) = %+v", want, *got)
+	}
+}
+
+func TestSearchCursorRoundTrip(t *testing.T) {
+	want := lts.SearchCursor{Rank: 0.0607927, Type: ItemNews, ID: 7, Fuzzy: true}
+
+	var got lts.SearchCursor
+	if err := decodeToken(encodeCursor(want), &got); err != nil {
+		t.Fatalf("decodeToken: %v", err)
+	}
+
+	if got != want {
+		t.Errorf("decodeToken(encodeCursor(%+v)) = %+v", want, got)
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		limit   int
+		wantErr bool
+	}{
+		{name: "first page", token: "", limit: 10},
+		{name: "valid token", token: encodeCursor(lts.ListCursor{ID: 5}), limit: 10},
+		{name: "zero limit", token: "", limit: 0, wantErr: true},
+		{name: "negative limit", token: encodeCursor(lts.ListCursor{ID: 5}), limit: -1, wantErr: true},
+		{name: "not base64", token: "%%%", limit: 10, wantErr: true},
+		{name: "not json", token: "bm90IGpzb24", limit: 10, wantErr: true},
+		{name: "no id", token: encodeCursor(lts.ListCursor{SortOrder: 1}), limit: 10, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeCursor(tt.token, tt.limit)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("decodeCursor(%q, %d): %v", tt.token, tt.limit, err)
+				}
+
+				return
+			}
+
+			var validation *ValidationError
+			if !errors.As(err, &validation) {
+				t.Errorf("decodeCursor(%q, %d) = %v, want a validation error", tt.token, tt.limit, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package service
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"international_site/internal/storage/models"
+	"international_site/internal/types"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"go.uber.org/zap"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+const datasheetFont = "go"
+
+// datasheetContactTypes are printed in the datasheet footer, in this order.
+var datasheetContactTypes = []string{"phone", "email", "address"}
+
+// datasheetLabels holds the fixed datasheet captions; unknown locales use English.
+var datasheetLabels = map[string]map[string]string{
+	"ru": {"sku": "Артикул", "category": "Категория", "specs": "Технические характеристики", "page": "Стр."},
+	"en": {"sku": "SKU", "category": "Category", "specs": "Specifications", "page": "Page"},
+	"pl": {"sku": "Symbol", "category": "Kategoria", "specs": "Dane techniczne", "page": "Str."},
+}
+
+func datasheetLabel(locale, key string) string {
+	if labels, ok := datasheetLabels[locale]; ok {
+		return labels[key]
+	}
+
+	return datasheetLabels["en"][key]
+}
+
+// datasheetCacheSize bounds the number of datasheets kept in memory.
+const datasheetCacheSize = 256
+
+type datasheetKey struct {
+	id     uint
+	locale string
+}
+
+type datasheetEntry struct {
+	key   datasheetKey
+	sheet *types.Datasheet
+}
+
+// datasheetCache keeps the last rendered datasheet per product and locale;
+// an entry is valid while the product's UpdatedAt is unchanged. When full,
+// the least recently used datasheet is dropped.
+type datasheetCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List // of *datasheetEntry, most recently used first
+	items map[datasheetKey]*list.Element
+}
+
+func newDatasheetCache(size int) *datasheetCache {
+	return &datasheetCache{
+		size:  size,
+		order: list.New(),
+		items: make(map[datasheetKey]*list.Element),
+	}
+}
+
+func (c *datasheetCache) get(key datasheetKey, modifiedAt time.Time) (*types.Datasheet, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	sheet := item.Value.(*datasheetEntry).sheet
+	if !sheet.ModifiedAt.Equal(modifiedAt) {
+		return nil, false
+	}
+
+	c.order.MoveToFront(item)
+
+	return sheet, true
+}
+
+func (c *datasheetCache) put(key datasheetKey, sheet *types.Datasheet) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if item, ok := c.items[key]; ok {
+		item.Value.(*datasheetEntry).sheet = sheet
+		c.order.MoveToFront(item)
+
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&datasheetEntry{key: key, sheet: sheet})
+
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*datasheetEntry).key)
+	}
+}
+
+// GetProductDatasheet renders the product datasheet PDF, or returns the cached
+// one when the product has not been updated since. Only site languages are
+// rendered, so the cache cannot be filled with made-up locales.
+func (i *Instance) GetProductDatasheet(locale string, id uint) (*types.Datasheet, error) {
+	languages, err := i.lts.GetLanguages()
+	if err != nil {
+		return nil, err
+	}
+
+	if !knownLanguage(languages, locale) {
+		return nil, &ValidationError{Problems: []string{fmt.Sprintf("locale: unknown language %q", locale)}}
+	}
+
+	product, err := i.GetProductByID(locale, id)
+	if err != nil {
+		return nil, err
+	}
+
+	key := datasheetKey{id: id, locale: locale}
+
+	if sheet, ok := i.datasheets.get(key, product.UpdatedAt); ok {
+		return sheet, nil
+	}
+
+	var contacts []models.Contact
+
+	for _, contactType := range datasheetContactTypes {
+		byType, err := i.lts.GetContactsByType(contactType, locale)
+		if err != nil {
+			return nil, err
+		}
+
+		contacts = append(contacts, byType...)
+	}
+
+	content, err := i.renderDatasheet(locale, product, contacts)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := &types.Datasheet{
+		Content:    content,
+		FileName:   product.SKU + ".pdf",
+		ModifiedAt: product.UpdatedAt,
+		ETag:       fmt.Sprintf(`"%d-%s-%d"`, id, locale, product.UpdatedAt.UnixNano()),
+	}
+
+	i.datasheets.put(key, sheet)
+
+	return sheet, nil
+}
+
+func knownLanguage(languages []models.Language, code string) bool {
+	for _, lang := range languages {
+		if lang.Code == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (i *Instance) renderDatasheet(locale string, product *models.Product, contacts []models.Contact) ([]byte, error) {
+	name := product.SKU
+	if len(product.Translations) > 0 && product.Translations[0].Name != "" {
+		name = product.Translations[0].Name
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes(datasheetFont, "", goregular.TTF)
+	pdf.AddUTF8FontFromBytes(datasheetFont, "B", gobold.TTF)
+	pdf.SetTitle(name, true)
+	pdf.SetAuthor(i.cfg.CompanyName, true)
+	pdf.SetCreationDate(product.UpdatedAt)
+	pdf.SetModificationDate(product.UpdatedAt)
+	pdf.SetMargins(15, 20, 15)
+	pdf.SetAutoPageBreak(true, 25)
+	pdf.AliasNbPages("")
+
+	pageWidth, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	contentWidth := pageWidth - left - right
+
+	pdf.SetHeaderFunc(func() {
+		pdf.SetFont(datasheetFont, "B", 11)
+		pdf.CellFormat(contentWidth/2, 6, i.cfg.CompanyName, "", 0, "L", false, 0, "")
+		pdf.SetFont(datasheetFont, "", 9)
+		pdf.CellFormat(contentWidth/2, 6, i.cfg.SiteURL, "", 1, "R", false, 0, "")
+		pdf.Line(left, pdf.GetY()+1, pageWidth-right, pdf.GetY()+1)
+		pdf.Ln(6)
+	})
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-20)
+		pdf.SetFont(datasheetFont, "", 8)
+
+		parts := make([]string, 0, len(contacts))
+		for _, contact := range contacts {
+			parts = append(parts, contact.Value)
+		}
+
+		pdf.MultiCell(contentWidth, 4, strings.Join(parts, "  ·  "), "T", "C", false)
+		pdf.CellFormat(contentWidth, 5, fmt.Sprintf("%s %d/{nb}", datasheetLabel(locale, "page"), pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+
+	pdf.AddPage()
+
+	pdf.SetFont(datasheetFont, "B", 18)
+	pdf.MultiCell(contentWidth, 8, name, "", "L", false)
+	pdf.Ln(2)
+
+	pdf.SetFont(datasheetFont, "", 10)
+	pdf.CellFormat(contentWidth, 5, fmt.Sprintf("%s: %s", datasheetLabel(locale, "sku"), product.SKU), "", 1, "L", false, 0, "")
+
+	if len(product.Category.Translations) > 0 {
+		pdf.CellFormat(contentWidth, 5, fmt.Sprintf("%s: %s", datasheetLabel(locale, "category"), product.Category.Translations[0].Name), "", 1, "L", false, 0, "")
+	}
+
+	pdf.Ln(4)
+
+	i.drawDatasheetImage(pdf, product.PrimaryImageURL, contentWidth)
+
+	if len(product.Translations) > 0 {
+		trans := product.Translations[0]
+
+		if trans.ShortDesc != "" {
+			pdf.SetFont(datasheetFont, "B", 11)
+			pdf.MultiCell(contentWidth, 6, trans.ShortDesc, "", "L", false)
+			pdf.Ln(2)
+		}
+
+		if trans.Description != "" {
+			pdf.SetFont(datasheetFont, "", 10)
+			pdf.MultiCell(contentWidth, 5, trans.Description, "", "L", false)
+			pdf.Ln(4)
+		}
+	}
+
+	drawDatasheetSpecs(pdf, locale, product.Specs, contentWidth)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// drawDatasheetImage places the image centred, at most 120x80 mm. Images that
+// are remote, missing or in a format fpdf cannot read are skipped.
+func (i *Instance) drawDatasheetImage(pdf *fpdf.Fpdf, imageURL string, contentWidth float64) {
+	if imageURL == "" || i.cfg.MediaDir == "" || strings.Contains(imageURL, "://") {
+		return
+	}
+
+	file := filepath.Join(i.cfg.MediaDir, filepath.FromSlash(path.Clean("/"+imageURL)))
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		i.logger.Warn("datasheet image is not available", zap.String("file", file), zap.Error(err))
+		return
+	}
+
+	imageType := strings.TrimPrefix(filepath.Ext(file), ".")
+
+	info := pdf.RegisterImageOptionsReader(imageURL, fpdf.ImageOptions{ImageType: imageType}, bytes.NewReader(data))
+	if err := pdf.Error(); err != nil {
+		i.logger.Warn("datasheet image cannot be embedded", zap.String("file", file), zap.Error(err))
+		pdf.ClearError()
+
+		return
+	}
+
+	width, height := info.Extent()
+	scale := min(120/width, 80/height, 1)
+	width, height = width*scale, height*scale
+
+	left, _, _, _ := pdf.GetMargins()
+	pdf.ImageOptions(imageURL, left+(contentWidth-width)/2, pdf.GetY(), width, height, false, fpdf.ImageOptions{}, 0, "")
+	pdf.SetY(pdf.GetY() + height + 6)
+}
+
+// drawDatasheetSpecs prints specs as a two-column table with wrapped cells.
+func drawDatasheetSpecs(pdf *fpdf.Fpdf, locale string, specs []models.ProductSpec, contentWidth float64) {
+	if len(specs) == 0 {
+		return
+	}
+
+	const lineHeight = 5.0
+
+	nameWidth := contentWidth * 0.4
+	valueWidth := contentWidth - nameWidth
+
+	pdf.SetFont(datasheetFont, "B", 12)
+	pdf.CellFormat(contentWidth, 8, datasheetLabel(locale, "specs"), "", 1, "L", false, 0, "")
+
+	pdf.SetFont(datasheetFont, "", 10)
+	pdf.SetFillColor(240, 240, 240)
+
+	for idx, spec := range specs {
+		if len(spec.Translations) == 0 {
+			continue
+		}
+
+		name, value := spec.Translations[0].Name, spec.Translations[0].Value
+
+		nameLines := pdf.SplitText(name, nameWidth-2)
+		valueLines := pdf.SplitText(value, valueWidth-2)
+		height := float64(max(len(nameLines), len(valueLines), 1)) * lineHeight
+
+		_, pageHeight := pdf.GetPageSize()
+		_, _, _, bottom := pdf.GetMargins()
+		if pdf.GetY()+height > pageHeight-bottom {
+			pdf.AddPage()
+		}
+
+		x, y := pdf.GetXY()
+
+		style := "D"
+		if idx%2 == 0 {
+			style = "FD"
+		}
+
+		pdf.Rect(x, y, contentWidth, height, style)
+		pdf.Line(x+nameWidth, y, x+nameWidth, y+height)
+		pdf.MultiCell(nameWidth, lineHeight, strings.Join(nameLines, "\n"), "", "L", false)
+		pdf.SetXY(x+nameWidth, y)
+		pdf.MultiCell(valueWidth, lineHeight, strings.Join(valueLines, "\n"), "", "L", false)
+		pdf.SetXY(x, y+height)
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"international_site/internal/types"
+	"testing"
+	"time"
+)
+
+func TestDatasheetCache(t *testing.T) {
+	modifiedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	sheet := func(name string) *types.Datasheet {
+		return &types.Datasheet{FileName: name, ModifiedAt: modifiedAt}
+	}
+
+	cache := newDatasheetCache(2)
+	first := datasheetKey{id: 1, locale: "en"}
+	second := datasheetKey{id: 1, locale: "pl"}
+	third := datasheetKey{id: 2, locale: "en"}
+
+	cache.put(first, sheet("first.pdf"))
+	cache.put(second, sheet("second.pdf"))
+
+	if _, ok := cache.get(first, modifiedAt); !ok {
+		t.Fatal("first datasheet is missing")
+	}
+
+	// first was used last, so second is the one to go
+	cache.put(third, sheet("third.pdf"))
+
+	tests := []struct {
+		name string
+		key  datasheetKey
+		want string
+	}{
+		{"recently used", first, "first.pdf"},
+		{"evicted", second, ""},
+		{"newest", third, "third.pdf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := cache.get(tt.key, modifiedAt)
+			if tt.want == "" {
+				if ok {
+					t.Fatalf("get(%v) = %s, want a miss", tt.key, got.FileName)
+				}
+
+				return
+			}
+
+			if !ok || got.FileName != tt.want {
+				t.Fatalf("get(%v) = %v, %v, want %s", tt.key, got, ok, tt.want)
+			}
+		})
+	}
+
+	if _, ok := cache.get(first, modifiedAt.Add(time.Second)); ok {
+		t.Error("get() returned a datasheet of an older product version")
+	}
+
+	if cache.order.Len() != 2 || len(cache.items) != 2 {
+		t.Errorf("cache holds %d/%d entries, want 2", cache.order.Len(), len(cache.items))
+	}
+}
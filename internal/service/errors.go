@@ -0,0 +1,22 @@
+package service
+
+import (
+	"international_site/internal/storage/lts"
+	"strings"
+)
+
+var (
+	// ErrNotFound is returned when the requested entity does not exist.
+	ErrNotFound = lts.ErrNotFound
+	// ErrConflict is returned when a write clashes with existing data, e.g. a duplicate SKU.
+	ErrConflict = lts.ErrConflict
+)
+
+// ValidationError lists every problem found in a client request.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "validation failed: " + strings.Join(e.Problems, "; ")
+}
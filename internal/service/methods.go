@@ -25,8 +25,17 @@ func (i *Instance) loadTemplates() {
 	i.templates = make(map[string]*template.Template)
 }
 
-func (i *Instance) GetPageBySlug(slug, locale string) (*models.Page, error) {
-	return i.lts.GetPageBySlug(slug, locale)
+func (i *Instance) GetPageBySlug(slug, locale, preview string) (*models.Page, error) {
+	page, err := i.lts.GetPageBySlug(slug, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	if page.Status != models.StatusPublished && !i.validPreview(preview, ItemPage, page.ID) {
+		return nil, ErrNotFound
+	}
+
+	return page, nil
 }
 
 func (i *Instance) GetProductCategories(locale string) ([]models.ProductCategory, error) {
@@ -41,32 +50,131 @@ func (i *Instance) GetCategoryByID(locale string, id uint) (*models.ProductCateg
 	return i.lts.GetCategoryByID(id, locale)
 }
 
+// GetCategoryTree nests the flat category list under parent_id, keeping each level in sort_order.
+func (i *Instance) GetCategoryTree(locale string) ([]types.CategoryDTO, error) {
+	rows, err := i.lts.GetCategoryTree(locale)
+	if err != nil {
+		return nil, err
+	}
+
+	byParent := make(map[uint][]types.CategoryDTO)
+	for _, row := range rows {
+		var parentID uint
+		if row.ParentID != nil {
+			parentID = *row.ParentID
+		}
+
+		byParent[parentID] = append(byParent[parentID], types.CategoryDTO{
+			ID:                row.ID,
+			Name:              row.Name,
+			Description:       row.Description,
+			Slug:              row.Slug,
+			ParentID:          row.ParentID,
+			SortOrder:         row.SortOrder,
+			ProductCount:      row.ProductCount,
+			TotalProductCount: row.TotalProductCount,
+		})
+	}
+
+	var build func(parentID uint) []types.CategoryDTO
+	build = func(parentID uint) []types.CategoryDTO {
+		nodes := byParent[parentID]
+		for idx := range nodes {
+			nodes[idx].Children = build(nodes[idx].ID)
+		}
+
+		return nodes
+	}
+
+	tree := build(0)
+	if tree == nil {
+		tree = []types.CategoryDTO{}
+	}
+
+	return tree, nil
+}
+
 func (i *Instance) GetProducts(locale string, offset, limit int) ([]models.Product, int, error) {
-	return i.lts.GetProducts(locale, offset, limit)
+	products, total, err := i.lts.GetProducts(locale, offset, limit)
+	i.prepareProducts(locale, products)
+
+	return products, total, err
 }
 
-func (i *Instance) GetProductsByCategory(locale string, categoryID uint, offset, limit int) ([]models.Product, int, error) {
-	return i.lts.GetProductsByCategory(locale, categoryID, offset, limit)
+func (i *Instance) GetProductsByCategory(locale string, categoryID uint, includeDescendants bool, offset, limit int) ([]models.Product, int, error) {
+	products, total, err := i.lts.GetProductsByCategory(locale, categoryID, includeDescendants, offset, limit)
+	i.prepareProducts(locale, products)
+
+	return products, total, err
 }
 
+// GetProductByID returns a published product with its published variants.
 func (i *Instance) GetProductByID(locale string, id uint) (*models.Product, error) {
-	return i.lts.GetProductByID(id, locale)
+	return i.getProduct(locale, id, "")
 }
 
-func (i *Instance) GetRelatedProducts(locale string, categoryID, excludeID uint, limit int) ([]models.Product, error) {
-	return i.lts.GetRelatedProducts(locale, categoryID, excludeID, limit)
+// getProduct is GetProductByID that also shows an unpublished product and its
+// unpublished variants to the holder of a preview token for it.
+func (i *Instance) getProduct(locale string, id uint, preview string) (*models.Product, error) {
+	product, err := i.lts.GetProductByID(id, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	if !i.validPreview(preview, ItemProduct, id) {
+		if product.Status != models.StatusPublished {
+			return nil, ErrNotFound
+		}
+
+		variants := product.Variants[:0]
+		for _, variant := range product.Variants {
+			if variant.Status == models.StatusPublished {
+				variants = append(variants, variant)
+			}
+		}
+
+		product.Variants = variants
+	}
+
+	i.prepareProduct(locale, product)
+
+	return product, nil
+}
+
+func (i *Instance) GetRelatedProducts(locale string, productID uint, limit int) ([]types.RelatedProduct, error) {
+	rows, err := i.lts.GetRelatedProducts(locale, productID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	related := make([]types.RelatedProduct, 0, len(rows))
+	for _, row := range rows {
+		i.prepareProduct(locale, &row.Product)
+		related = append(related, types.RelatedProduct{Product: row.Product, Score: row.Score, Relation: row.Relation})
+	}
+
+	return related, nil
 }
 
 func (i *Instance) GetNews(locale string, offset, limit int) ([]models.News, int, error) {
-	return i.lts.GetNews(locale, offset, limit)
+	return i.lts.GetNews(locale, i.NowFunc(), offset, limit)
 }
 
-func (i *Instance) GetNewsByID(locale string, id uint) (*models.News, error) {
-	return i.lts.GetNewsByID(id, locale)
+func (i *Instance) GetNewsByID(locale string, id uint, preview string) (*models.News, error) {
+	news, err := i.lts.GetNewsByID(id, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	if !newsLive(news, i.NowFunc()) && !i.validPreview(preview, ItemNews, id) {
+		return nil, ErrNotFound
+	}
+
+	return news, nil
 }
 
 func (i *Instance) GetRecentNews(locale string, limit int) ([]models.News, error) {
-	news, _, err := i.lts.GetNews(locale, 0, limit)
+	news, _, err := i.lts.GetNews(locale, i.NowFunc(), 0, limit)
 	return news, err
 }
 
@@ -79,134 +187,65 @@ func (i *Instance) GetContactsByType(contactType, locale string) ([]models.Conta
 }
 
 func (i *Instance) SearchProducts(locale, query string, offset, limit int) ([]models.Product, int, error) {
-	return i.lts.SearchProducts(locale, query, offset, limit)
+	products, total, err := i.lts.SearchProducts(locale, query, offset, limit)
+	i.prepareProducts(locale, products)
+
+	return products, total, err
 }
 
-func (i *Instance) SearchAll(locale, query string) ([]types.SearchResult, error) {
-	var results []types.SearchResult
+func (i *Instance) FilterProducts(filter types.ProductFilter) ([]models.Product, int, error) {
+	resolved, err := i.storageFilter(filter)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	products, _, err := i.lts.SearchProducts(locale, query, 0, 10)
-	if err == nil {
-		for _, p := range products {
-			var productName string
-			for _, trans := range p.Translations {
-				if trans.LanguageCode == locale {
-					productName = trans.Name
-					break
-				}
-			}
-			if productName == "" && len(p.Translations) > 0 {
-				productName = p.Translations[0].Name
-			}
+	products, total, err := i.lts.FilterProducts(resolved)
+	i.prepareProducts(filter.Locale, products)
 
-			var shortDesc string
-			for _, trans := range p.Translations {
-				if trans.LanguageCode == locale {
-					shortDesc = trans.ShortDesc
-					break
-				}
-			}
+	return products, total, err
+}
 
-			results = append(results, types.SearchResult{
-				Type:        "product",
-				ID:          p.ID,
-				Title:       productName,
-				Description: shortDesc,
-				URL:         fmt.Sprintf("/%s/product/%d", locale, p.ID),
-				Image:       p.ImageURL,
-			})
-		}
+func (i *Instance) GetSpecFacets(filter types.ProductFilter) ([]types.SpecFacet, error) {
+	resolved, err := i.storageFilter(filter)
+	if err != nil {
+		return nil, err
 	}
 
-	news, _, err := i.lts.SearchNews(locale, query, 0, 10)
-	if err == nil {
-		for _, n := range news {
-			var newsTitle, excerpt string
-			for _, trans := range n.Translations {
-				if trans.LanguageCode == locale {
-					newsTitle = trans.Title
-					excerpt = trans.Excerpt
-					break
-				}
-			}
-
-			results = append(results, types.SearchResult{
-				Type:        "news",
-				ID:          n.ID,
-				Title:       newsTitle,
-				Description: excerpt,
-				URL:         fmt.Sprintf("/%s/news/%d", locale, n.ID),
-				Image:       n.ImageURL,
-				Date:        n.CreatedAt.Format("02.01.2006"),
-			})
-		}
+	rows, err := i.lts.GetSpecFacets(resolved)
+	if err != nil {
+		return nil, err
 	}
 
-	pages, err := i.lts.SearchPages(locale, query)
-	if err == nil {
-		for _, p := range pages {
-			var pageTitle string
-			for _, trans := range p.Translations {
-				if trans.LanguageCode == locale {
-					pageTitle = trans.Title
-					break
-				}
-			}
+	facets := make([]types.SpecFacet, 0, len(rows))
+	for _, row := range rows {
+		facet := types.SpecFacet{Name: row.Name, Min: row.Min, Max: row.Max, Dimension: row.Dimension}
 
-			results = append(results, types.SearchResult{
-				Type:        "page",
-				ID:          p.ID,
-				Title:       pageTitle,
-				Description: "",
-				URL:         fmt.Sprintf("/%s/page/%s", locale, p.Slug),
-			})
+		for _, value := range row.Values {
+			facet.Values = append(facet.Values, types.FacetValue{Value: value.Value, Count: value.Count})
 		}
-	}
 
-	docs, err := i.lts.SearchDocuments(locale, query)
-	if err == nil {
-		for _, d := range docs {
-			var docTitle, docDesc string
-			for _, trans := range d.Translations {
-				if trans.LanguageCode == locale {
-					docTitle = trans.Title
-					docDesc = trans.Description
-					break
-				}
+		if facet.Min != nil && facet.Max != nil {
+			if minValue, symbol, ok := i.displayValue(filter.Locale, facet.Dimension, *facet.Min); ok {
+				maxValue, _, _ := i.displayValue(filter.Locale, facet.Dimension, *facet.Max)
+				facet.Min, facet.Max, facet.Unit = &minValue, &maxValue, symbol
 			}
-
-			results = append(results, types.SearchResult{
-				Type:        "document",
-				ID:          d.ID,
-				Title:       docTitle,
-				Description: docDesc,
-				URL:         d.FileURL,
-			})
 		}
-	}
-
-	return results, nil
-}
-
-func (i *Instance) SearchAPI(locale, query string, limit int) ([]types.SearchResult, error) {
-	results, err := i.SearchAll(locale, query)
-	if err != nil {
-		return nil, err
-	}
 
-	if len(results) > limit {
-		results = results[:limit]
+		facets = append(facets, facet)
 	}
 
-	return results, nil
-}
-
-func (i *Instance) FilterProducts(locale string, categoryID uint, search, sortBy, sortOrder string, offset, limit int) ([]models.Product, int, error) {
-	return i.lts.FilterProducts(locale, categoryID, search, sortBy, sortOrder, offset, limit)
+	return facets, nil
 }
 
 func (i *Instance) GetProductsSorted(locale, search, sortBy, sortOrder string, offset, limit int) ([]models.Product, int, error) {
-	return i.FilterProducts(locale, 0, search, sortBy, sortOrder, offset, limit)
+	return i.FilterProducts(types.ProductFilter{
+		Locale:    locale,
+		Search:    search,
+		SortBy:    sortBy,
+		SortOrder: sortOrder,
+		Offset:    offset,
+		Limit:     limit,
+	})
 }
 
 func (i *Instance) SaveFeedback(feedback types.FeedbackRequest) (uint, error) {
@@ -265,7 +304,7 @@ func (i *Instance) GenerateSitemap(locale string) (string, error) {
 		}
 	}
 
-	news, _, err := i.lts.GetNews(locale, 0, 100)
+	news, _, err := i.lts.GetNews(locale, i.NowFunc(), 0, 100)
 	if err == nil {
 		for _, item := range news {
 			builder.WriteString(fmt.Sprintf(`
@@ -274,7 +313,7 @@ func (i *Instance) GenerateSitemap(locale string) (string, error) {
 		<priority>0.5</priority>
 		<changefreq>yearly</changefreq>
 		<lastmod>%s</lastmod>
-	</url>`, i.cfg.SiteURL, locale, item.ID, item.CreatedAt.Format("2006-01-02")))
+	</url>`, i.cfg.SiteURL, locale, item.ID, newsDate(item).Format("2006-01-02")))
 		}
 	}
 
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"international_site/internal/storage/lts"
+	"international_site/internal/storage/models"
+	"international_site/internal/types"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// defaultSchedulerInterval is used when the config sets no scheduler interval.
+const defaultSchedulerInterval = time.Minute
+
+// newsLive reports whether a news item is published and inside its
+// publication window at now.
+func newsLive(news *models.News, now time.Time) bool {
+	return news.Status == models.StatusPublished &&
+		(news.PublishAt == nil || !news.PublishAt.After(now)) &&
+		(news.UnpublishAt == nil || news.UnpublishAt.After(now))
+}
+
+// newsDate is the date a news item is shown and listed under.
+func newsDate(news models.News) time.Time {
+	if news.PublishAt != nil {
+		return *news.PublishAt
+	}
+
+	return news.CreatedAt
+}
+
+// utcTime drops the offset an admin sent the time with, so that the instant
+// is stored and compared the same whatever zone the server runs in.
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	utc := t.UTC()
+
+	return &utc
+}
+
+// SetNewsSchedule sets the publication window of a news item; a nil bound
+// means no limit on that side.
+func (i *Instance) SetNewsSchedule(id uint, req types.NewsScheduleRequest, author string) error {
+	if req.PublishAt != nil && req.UnpublishAt != nil && !req.UnpublishAt.After(*req.PublishAt) {
+		return &ValidationError{Problems: []string{"unpublish_at: must be later than publish_at"}}
+	}
+
+	return i.tracked(ItemNews, id, author, func(store lts.Protocol) error {
+		return store.SetNewsSchedule(id, utcTime(req.PublishAt), utcTime(req.UnpublishAt))
+	})
+}
+
+// RunNewsScheduler logs an event whenever a scheduled news item goes live or
+// expires, until ctx is cancelled. Listings and the sitemap check the window
+// on every request, so the job only reports what has changed.
+func (i *Instance) RunNewsScheduler(ctx context.Context) {
+	interval := i.cfg.SchedulerInterval
+	if interval <= 0 {
+		interval = defaultSchedulerInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := i.NowFunc()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := i.NowFunc()
+
+		// on failure last stays put, so the missed window is checked again
+		if err := i.reportNewsSchedule(last, now); err != nil {
+			i.logger.Error("news scheduler failed", zap.Error(err))
+			continue
+		}
+
+		last = now
+	}
+}
+
+func (i *Instance) reportNewsSchedule(from, to time.Time) error {
+	live, err := i.lts.GetNewsGoingLive(from, to)
+	if err != nil {
+		return err
+	}
+
+	expired, err := i.lts.GetNewsExpiring(from, to)
+	if err != nil {
+		return err
+	}
+
+	for _, news := range live {
+		i.logger.Info("news published on schedule", zap.Uint("news_id", news.ID), zap.Timep("publish_at", news.PublishAt))
+	}
+
+	for _, news := range expired {
+		i.logger.Info("news unpublished on schedule", zap.Uint("news_id", news.ID), zap.Timep("unpublish_at", news.UnpublishAt))
+	}
+
+	return nil
+}
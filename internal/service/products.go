@@ -0,0 +1,39 @@
+package service
+
+import "international_site/internal/storage/models"
+
+// prepareProducts fills the derived, non-persisted fields of products for locale.
+func (i *Instance) prepareProducts(locale string, products []models.Product) {
+	for idx := range products {
+		i.prepareProduct(locale, &products[idx])
+	}
+}
+
+func (i *Instance) prepareProduct(locale string, product *models.Product) {
+	i.localizeSpecs(locale, product.Specs)
+	fillAvailability(product)
+	fillPrimaryImage(product)
+	i.prepareVariants(locale, product)
+
+	// per-warehouse counts stay in the admin API
+	product.StockLevel = stockLevel(product.AvailableQuantity)
+	product.Stock = nil
+}
+
+// fillPrimaryImage picks the primary gallery image, preferring one made for
+// the locale, and falls back to the legacy ImageURL.
+func fillPrimaryImage(product *models.Product) {
+	product.PrimaryImageURL = product.ImageURL
+
+	for _, media := range product.Media {
+		if media.Kind != models.MediaImage || !media.IsPrimary {
+			continue
+		}
+
+		product.PrimaryImageURL = media.URL
+
+		if media.LanguageCode != nil {
+			return
+		}
+	}
+}
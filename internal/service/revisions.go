@@ -0,0 +1,353 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"international_site/internal/storage/lts"
+	"international_site/internal/storage/models"
+	"international_site/internal/types"
+	"sort"
+	"strconv"
+)
+
+// revisionIgnoredKeys are left out of revision diffs: row ids and references
+// to the parent row, which change whenever children are rewritten, and timestamps.
+var revisionIgnoredKeys = map[string]bool{
+	"id":         true,
+	"created_at": true,
+	"updated_at": true,
+	"product_id": true,
+	"page_id":    true,
+	"news_id":    true,
+	"spec_id":    true,
+	"media_id":   true,
+	"option_id":  true,
+}
+
+func snapshot(store lts.Protocol, itemType string, id uint) (any, error) {
+	switch itemType {
+	case ItemProduct:
+		return store.GetProductSnapshot(id)
+	case ItemPage:
+		return store.GetPageSnapshot(id)
+	case ItemNews:
+		return store.GetNewsSnapshot(id)
+	}
+
+	return nil, fmt.Errorf("%s has no revisions", itemType)
+}
+
+// tracked runs write and the revisions around it in one transaction: the
+// state before the first tracked write, so that the first change can be
+// rolled back as well, and the state after it. When a revision cannot be
+// stored the write is rolled back too.
+func (i *Instance) tracked(itemType string, id uint, author string, write func(store lts.Protocol) error) error {
+	return i.lts.Transaction(func(store lts.Protocol) error {
+		if err := saveBaseline(store, itemType, id); err != nil {
+			return err
+		}
+
+		if err := write(store); err != nil {
+			return err
+		}
+
+		return saveRevision(store, itemType, id, author)
+	})
+}
+
+// saveBaseline stores the current state of an item that has no revisions yet.
+// A missing item is left for the write to report.
+func saveBaseline(store lts.Protocol, itemType string, id uint) error {
+	last, err := store.GetLastRevision(itemType, id)
+	if err != nil || last != nil {
+		return err
+	}
+
+	if err := saveRevision(store, itemType, id, ""); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+// saveRevision stores the current state of an item unless it matches the
+// latest revision.
+func saveRevision(store lts.Protocol, itemType string, id uint, author string) error {
+	current, err := snapshot(store, itemType, id)
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+
+	last, err := store.GetLastRevision(itemType, id)
+	if err != nil {
+		return err
+	}
+
+	revision := &models.Revision{
+		EntityType: itemType,
+		EntityID:   id,
+		Author:     author,
+		Snapshot:   snapshot,
+	}
+
+	if last != nil {
+		changes, err := diffSnapshots(last.Snapshot, snapshot)
+		if err != nil {
+			return err
+		}
+
+		if len(changes) == 0 {
+			return nil
+		}
+
+		if revision.Diff, err = json.Marshal(changes); err != nil {
+			return err
+		}
+	}
+
+	return store.CreateRevision(revision)
+}
+
+// GetRevisions lists the revisions of a page, product or news item, newest first.
+func (i *Instance) GetRevisions(itemType string, id uint) ([]models.Revision, error) {
+	return i.lts.GetRevisions(itemType, id)
+}
+
+func (i *Instance) GetRevision(id uint) (*models.Revision, error) {
+	return i.lts.GetRevision(id)
+}
+
+// CompareRevisions lists the changes from one revision of an item to another.
+func (i *Instance) CompareRevisions(fromID, toID uint) (*types.RevisionComparison, error) {
+	from, err := i.lts.GetRevision(fromID)
+	if err != nil {
+		return nil, fmt.Errorf("revision %d: %w", fromID, err)
+	}
+
+	to, err := i.lts.GetRevision(toID)
+	if err != nil {
+		return nil, fmt.Errorf("revision %d: %w", toID, err)
+	}
+
+	if from.EntityType != to.EntityType || from.EntityID != to.EntityID {
+		return nil, &ValidationError{Problems: []string{
+			fmt.Sprintf("revisions %d and %d belong to different items", fromID, toID),
+		}}
+	}
+
+	changes, err := diffSnapshots(from.Snapshot, to.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.RevisionComparison{From: fromID, To: toID, Changes: changes}, nil
+}
+
+// RestoreRevision writes the snapshot of a revision back and records the
+// result as a new revision. The publication status is not part of a restore.
+// A deleted product is created again under its old id as a draft; restore a
+// deleted variant after its parent.
+func (i *Instance) RestoreRevision(id uint, author string) (*models.Revision, error) {
+	revision, err := i.lts.GetRevision(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var write func(store lts.Protocol) error
+
+	switch revision.EntityType {
+	case ItemProduct:
+		var product models.Product
+		if err := json.Unmarshal(revision.Snapshot, &product); err != nil {
+			return nil, err
+		}
+
+		product.ID = revision.EntityID
+		write = func(store lts.Protocol) error { return restoreProduct(store, &product) }
+
+	case ItemPage:
+		var page models.Page
+		if err := json.Unmarshal(revision.Snapshot, &page); err != nil {
+			return nil, err
+		}
+
+		page.ID = revision.EntityID
+		write = func(store lts.Protocol) error { return store.UpdatePage(&page) }
+
+	case ItemNews:
+		var news models.News
+		if err := json.Unmarshal(revision.Snapshot, &news); err != nil {
+			return nil, err
+		}
+
+		news.ID = revision.EntityID
+		write = func(store lts.Protocol) error { return store.UpdateNews(&news) }
+
+	default:
+		return nil, fmt.Errorf("revision %d has unknown type %q", id, revision.EntityType)
+	}
+
+	err = i.lts.Transaction(func(store lts.Protocol) error {
+		if err := write(store); err != nil {
+			return err
+		}
+
+		return saveRevision(store, revision.EntityType, revision.EntityID, author)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return revision, nil
+}
+
+// restoreProduct writes a product snapshot back. Child rows are recreated, so
+// their old ids are dropped; options are matched by code as usual.
+func restoreProduct(store lts.Protocol, product *models.Product) error {
+	for idx := range product.Specs {
+		product.Specs[idx].ID = 0
+	}
+
+	for idx := range product.Media {
+		product.Media[idx].ID = 0
+	}
+
+	for idx := range product.Options {
+		option := &product.Options[idx]
+		option.ID = 0
+
+		for j := range option.Values {
+			option.Values[j].ID = 0
+		}
+	}
+
+	_, err := store.GetProductSnapshot(product.ID)
+	if errors.Is(err, ErrNotFound) {
+		return recreateProduct(store, product)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if product.ParentID == nil {
+		if err := store.SetProductOptions(product.ID, product.Options); err != nil {
+			return err
+		}
+	}
+
+	return store.UpdateProduct(product)
+}
+
+// recreateProduct inserts a deleted product again under its old id. It comes
+// back as a draft so that it is reviewed before it is shown again.
+func recreateProduct(store lts.Protocol, product *models.Product) error {
+	product.Status = models.StatusDraft
+
+	if err := store.CreateProduct(product); err != nil {
+		return err
+	}
+
+	if product.ParentID != nil {
+		return nil
+	}
+
+	return store.SetProductOptions(product.ID, product.Options)
+}
+
+// diffSnapshots compares two JSON snapshots field by field. Array items with
+// a language_code are matched by language, the rest by position.
+func diffSnapshots(from, to json.RawMessage) ([]types.FieldChange, error) {
+	oldFields, err := flattenSnapshot(from)
+	if err != nil {
+		return nil, err
+	}
+
+	newFields, err := flattenSnapshot(to)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(newFields))
+	for path := range newFields {
+		paths = append(paths, path)
+	}
+
+	for path := range oldFields {
+		if _, ok := newFields[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+
+	sort.Strings(paths)
+
+	changes := make([]types.FieldChange, 0)
+	for _, path := range paths {
+		if oldFields[path] != newFields[path] {
+			changes = append(changes, types.FieldChange{Column: path, Old: oldFields[path], New: newFields[path]})
+		}
+	}
+
+	return changes, nil
+}
+
+func flattenSnapshot(snapshot json.RawMessage) (map[string]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(snapshot))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]string)
+	flattenValue("", value, fields)
+
+	return fields, nil
+}
+
+func flattenValue(path string, value any, fields map[string]string) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if revisionIgnoredKeys[key] {
+				continue
+			}
+
+			if path != "" {
+				key = path + "." + key
+			}
+
+			flattenValue(key, item, fields)
+		}
+
+	case []any:
+		for idx, item := range v {
+			key := strconv.Itoa(idx)
+			if object, ok := item.(map[string]any); ok {
+				if code, ok := object["language_code"].(string); ok && code != "" {
+					key = code
+				}
+			}
+
+			flattenValue(fmt.Sprintf("%s[%s]", path, key), item, fields)
+		}
+
+	case nil:
+		fields[path] = ""
+
+	case string:
+		fields[path] = v
+
+	default:
+		fields[path] = fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,465 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"international_site/internal/storage/lts"
+	"international_site/internal/storage/models"
+	"international_site/internal/types"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+// searchTypes are the item types Search can be restricted to.
+var searchTypes = []string{ItemProduct, ItemNews, ItemPage, ItemDocument}
+
+// Search ranks matching products, news, pages and documents in one list. An
+// empty itemType searches every type; the facets always count every type.
+// SKU matches rank first, and a query that is a SKU also gets a redirect to
+// that product. When the full-text search finds nothing, similar titles are
+// returned instead, and a query with typos gets a corrected spelling.
+func (i *Instance) Search(locale, query, itemType string, offset, limit int) (*types.SearchResponse, error) {
+	var problems []string
+
+	if offset < 0 {
+		problems = append(problems, "offset: must not be negative")
+	}
+
+	response, err := newSearchResponse(query, itemType, limit, problems)
+	if err != nil || strings.TrimSpace(query) == "" {
+		return response, err
+	}
+
+	response.Offset = offset
+
+	hits, err := i.lts.Search(locale, query, i.NowFunc(), itemType, offset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+
+	response.Total = hits.Total
+	for _, facet := range hits.Facets {
+		response.Facets = append(response.Facets, types.SearchFacet{Type: facet.Type, Count: facet.Count})
+	}
+
+	if err := i.fillSearchResponse(response, locale, hits); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// SearchAfter is Search with keyset pagination: it returns up to limit
+// results after the cursor and the cursor of the next page, empty on the last
+// one. The total and the facets are not counted.
+func (i *Instance) SearchAfter(locale, query, itemType, cursor string, limit int) (*types.SearchResponse, error) {
+	var after *lts.SearchCursor
+	var problems []string
+
+	if cursor != "" {
+		after = &lts.SearchCursor{}
+		if err := decodeToken(cursor, after); err != nil || after.ID == 0 {
+			problems = append(problems, fmt.Sprintf("cursor: %q is not a valid cursor", cursor))
+		}
+	}
+
+	response, err := newSearchResponse(query, itemType, limit, problems)
+	if err != nil || strings.TrimSpace(query) == "" {
+		return response, err
+	}
+
+	hits, err := i.lts.SearchAfter(locale, query, i.NowFunc(), itemType, after, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+
+	if len(hits.Hits) > limit {
+		hits.Hits = hits.Hits[:limit]
+		last := hits.Hits[limit-1]
+
+		response.NextCursor = encodeCursor(lts.SearchCursor{Rank: last.Rank, Type: last.Type, ID: last.ID, Fuzzy: hits.Fuzzy})
+	}
+
+	if err := i.fillSearchResponse(response, locale, hits); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// newSearchResponse validates the parameters both search modes share and
+// returns the empty response.
+func newSearchResponse(query, itemType string, limit int, problems []string) (*types.SearchResponse, error) {
+	if itemType != "" && !slices.Contains(searchTypes, itemType) {
+		problems = append(problems, fmt.Sprintf("type: %q must be one of %s", itemType, strings.Join(searchTypes, ", ")))
+	}
+
+	if limit <= 0 {
+		problems = append(problems, "limit: must be positive")
+	}
+
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+
+	return &types.SearchResponse{
+		Query:   query,
+		Type:    itemType,
+		Results: []types.SearchResult{},
+		Facets:  []types.SearchFacet{},
+		Limit:   limit,
+	}, nil
+}
+
+// fillSearchResponse loads the results behind hits, suggests a correction
+// when the query matched only by similarity or not at all, and adds the
+// redirect of a SKU query.
+func (i *Instance) fillSearchResponse(response *types.SearchResponse, locale string, hits *lts.SearchHits) error {
+	results, err := i.searchResults(locale, hits.Hits)
+	if err != nil {
+		return err
+	}
+
+	response.Results = results
+	response.Fuzzy = hits.Fuzzy
+
+	if hits.Fuzzy || len(hits.Hits) == 0 {
+		if response.DidYouMean, err = i.didYouMean(locale, response.Query); err != nil {
+			return fmt.Errorf("search correction: %w", err)
+		}
+	}
+
+	if response.Type == "" || response.Type == ItemProduct {
+		product, err := i.lts.GetProductBySKU(response.Query)
+
+		switch {
+		case err == nil:
+			id := product.ID
+			if product.ParentID != nil {
+				id = *product.ParentID
+			}
+
+			response.Redirect = i.localeURL(locale, fmt.Sprintf("product/%d", id))
+		case !errors.Is(err, ErrNotFound):
+			return fmt.Errorf("search sku: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// queryWord is a word of a search query as the vocabulary splits names.
+var queryWord = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// correctionMinLength is the shortest word didYouMean corrects; shorter words
+// are similar to too much, and the vocabulary leaves them out as well.
+const correctionMinLength = 3
+
+// correctionMaxWords caps the words didYouMean looks up; the rest of a long
+// query is left as it is.
+const correctionMaxWords = 8
+
+// didYouMean replaces the words of the query that are not in the vocabulary
+// of locale with the closest ones, looked up in one query. Words with digits
+// are left alone, they are likely SKUs or sizes, and so is the spelling of
+// every word that is not replaced. It returns "" when there is nothing to
+// correct.
+func (i *Instance) didYouMean(locale, query string) (string, error) {
+	query = strings.TrimSpace(query)
+
+	var words []string
+	seen := make(map[string]bool)
+
+	for _, word := range queryWord.FindAllString(query, -1) {
+		word = strings.ToLower(word)
+		if seen[word] || utf8.RuneCountInString(word) < correctionMinLength || strings.ContainsFunc(word, unicode.IsDigit) {
+			continue
+		}
+
+		if len(words) == correctionMaxWords {
+			break
+		}
+
+		seen[word] = true
+		words = append(words, word)
+	}
+
+	if len(words) == 0 {
+		return "", nil
+	}
+
+	closest, err := i.lts.GetClosestWords(locale, words)
+	if err != nil {
+		return "", err
+	}
+
+	changed := false
+	corrected := queryWord.ReplaceAllStringFunc(query, func(word string) string {
+		lower := strings.ToLower(word)
+
+		replacement, ok := closest[lower]
+		if !ok || replacement == lower {
+			return word
+		}
+
+		changed = true
+
+		return matchCase(word, replacement)
+	})
+
+	if !changed {
+		return "", nil
+	}
+
+	return corrected, nil
+}
+
+// matchCase spells a lowercase replacement the way word is spelled: in
+// capitals, with a capital first letter or in lower case.
+func matchCase(word, replacement string) string {
+	first, _ := utf8.DecodeRuneInString(word)
+
+	switch {
+	case word == strings.ToUpper(word):
+		return strings.ToUpper(replacement)
+	case unicode.IsUpper(first):
+		r, size := utf8.DecodeRuneInString(replacement)
+		return string(unicode.ToUpper(r)) + replacement[size:]
+	}
+
+	return replacement
+}
+
+// searchResults loads the items behind hits, one query per type, and keeps
+// the order of hits. A hit whose item has gone in the meantime is skipped.
+func (i *Instance) searchResults(locale string, hits []lts.SearchHit) ([]types.SearchResult, error) {
+	ids := make(map[string][]uint, len(searchTypes))
+	for _, hit := range hits {
+		ids[hit.Type] = append(ids[hit.Type], hit.ID)
+	}
+
+	loaded := make(map[string]map[uint]types.SearchResult, len(searchTypes))
+
+	if len(ids[ItemProduct]) > 0 {
+		products, err := i.lts.GetProductsByIDs(ids[ItemProduct], locale)
+		if err != nil {
+			return nil, fmt.Errorf("search products: %w", err)
+		}
+
+		i.prepareProducts(locale, products)
+
+		loaded[ItemProduct] = make(map[uint]types.SearchResult, len(products))
+		for idx := range products {
+			loaded[ItemProduct][products[idx].ID] = productResult(locale, &products[idx])
+		}
+	}
+
+	if len(ids[ItemNews]) > 0 {
+		news, err := i.lts.GetNewsByIDs(ids[ItemNews], locale)
+		if err != nil {
+			return nil, fmt.Errorf("search news: %w", err)
+		}
+
+		loaded[ItemNews] = make(map[uint]types.SearchResult, len(news))
+		for idx := range news {
+			loaded[ItemNews][news[idx].ID] = newsResult(locale, &news[idx])
+		}
+	}
+
+	if len(ids[ItemPage]) > 0 {
+		pages, err := i.lts.GetPagesByIDs(ids[ItemPage], locale)
+		if err != nil {
+			return nil, fmt.Errorf("search pages: %w", err)
+		}
+
+		loaded[ItemPage] = make(map[uint]types.SearchResult, len(pages))
+		for idx := range pages {
+			loaded[ItemPage][pages[idx].ID] = pageResult(locale, &pages[idx])
+		}
+	}
+
+	if len(ids[ItemDocument]) > 0 {
+		documents, err := i.lts.GetDocumentsByIDs(ids[ItemDocument], locale)
+		if err != nil {
+			return nil, fmt.Errorf("search documents: %w", err)
+		}
+
+		loaded[ItemDocument] = make(map[uint]types.SearchResult, len(documents))
+		for idx := range documents {
+			loaded[ItemDocument][documents[idx].ID] = documentResult(&documents[idx])
+		}
+	}
+
+	results := make([]types.SearchResult, 0, len(hits))
+
+	for _, hit := range hits {
+		result, ok := loaded[hit.Type][hit.ID]
+		if !ok {
+			continue
+		}
+
+		result.Score = hit.Rank
+		result.Snippet = hit.Snippet
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func productResult(locale string, product *models.Product) types.SearchResult {
+	result := types.SearchResult{
+		Type:  ItemProduct,
+		ID:    product.ID,
+		Title: product.SKU,
+		URL:   fmt.Sprintf("/%s/product/%d", locale, product.ID),
+		Image: product.PrimaryImageURL,
+	}
+
+	if len(product.Translations) > 0 {
+		result.Title = product.Translations[0].Name
+		result.Description = product.Translations[0].ShortDesc
+	}
+
+	return result
+}
+
+func newsResult(locale string, news *models.News) types.SearchResult {
+	result := types.SearchResult{
+		Type:  ItemNews,
+		ID:    news.ID,
+		URL:   fmt.Sprintf("/%s/news/%d", locale, news.ID),
+		Image: news.ImageURL,
+		Date:  newsDate(*news).Format("02.01.2006"),
+	}
+
+	if len(news.Translations) > 0 {
+		result.Title = news.Translations[0].Title
+		result.Description = news.Translations[0].Excerpt
+	}
+
+	return result
+}
+
+func pageResult(locale string, page *models.Page) types.SearchResult {
+	result := types.SearchResult{
+		Type:  ItemPage,
+		ID:    page.ID,
+		Title: page.Slug,
+		URL:   fmt.Sprintf("/%s/page/%s", locale, page.Slug),
+	}
+
+	if len(page.Translations) > 0 {
+		result.Title = page.Translations[0].Title
+		result.Description = page.Translations[0].MetaDesc
+	}
+
+	return result
+}
+
+func documentResult(document *models.Document) types.SearchResult {
+	result := types.SearchResult{
+		Type: ItemDocument,
+		ID:   document.ID,
+		URL:  document.FileURL,
+	}
+
+	if len(document.Translations) > 0 {
+		result.Title = document.Translations[0].Title
+		result.Description = document.Translations[0].Description
+	}
+
+	return result
+}
+
+// defaultVocabularyInterval is used when the config sets no vocabulary interval.
+const defaultVocabularyInterval = time.Hour
+
+// RunVocabularyRefresh rebuilds the vocabulary of "did you mean" corrections
+// from the catalog at start and then every vocabulary interval, until ctx is
+// cancelled.
+func (i *Instance) RunVocabularyRefresh(ctx context.Context) {
+	interval := i.cfg.VocabularyInterval
+	if interval <= 0 {
+		interval = defaultVocabularyInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		i.refreshVocabulary()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refreshVocabulary rebuilds the vocabulary right away, after a change to the
+// published catalog. The change is saved by then, so a failure is only
+// logged; the periodic refresh catches up.
+func (i *Instance) refreshVocabulary() {
+	if err := i.lts.RefreshSearchVocabulary(); err != nil {
+		i.logger.Error("search vocabulary refresh failed", zap.Error(err))
+	}
+}
+
+// suggestMinLength is the shortest text Suggest completes; shorter prefixes
+// match too much to be useful and cannot use the trigram indexes.
+const suggestMinLength = 2
+
+// Suggest completes the text typed in the search box with product names,
+// SKUs, categories and page titles, each linking to its canonical URL.
+func (i *Instance) Suggest(locale, query string, limit int) (*types.Suggestions, error) {
+	if limit <= 0 {
+		return nil, &ValidationError{Problems: []string{"limit: must be positive"}}
+	}
+
+	suggestions := &types.Suggestions{
+		Query:      query,
+		Products:   []types.Suggestion{},
+		SKUs:       []types.Suggestion{},
+		Categories: []types.Suggestion{},
+		Pages:      []types.Suggestion{},
+	}
+
+	text := strings.TrimSpace(query)
+	if utf8.RuneCountInString(text) < suggestMinLength {
+		return suggestions, nil
+	}
+
+	hits, err := i.lts.Suggest(locale, text, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, hit := range hits {
+		suggestion := types.Suggestion{ID: hit.ID, Title: hit.Title, SKU: hit.SKU}
+
+		switch hit.Type {
+		case ItemProduct:
+			suggestion.URL = i.localeURL(locale, fmt.Sprintf("product/%d", hit.ID))
+			suggestions.Products = append(suggestions.Products, suggestion)
+		case "sku":
+			suggestion.URL = i.localeURL(locale, fmt.Sprintf("product/%d", hit.ID))
+			suggestions.SKUs = append(suggestions.SKUs, suggestion)
+		case ItemCategory:
+			suggestion.URL = i.localeURL(locale, "products/"+hit.Slug)
+			suggestions.Categories = append(suggestions.Categories, suggestion)
+		case ItemPage:
+			suggestion.URL = i.localeURL(locale, "page/"+hit.Slug)
+			suggestions.Pages = append(suggestions.Pages, suggestion)
+		}
+	}
+
+	return suggestions, nil
+}
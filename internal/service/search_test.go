@@ -0,0 +1,88 @@
+package service
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDidYouMean(t *testing.T) {
+	closest := map[string]string{
+		"прес":      "пресс",
+		"гидравлик": "гидравлический",
+		"станок":    "станок",
+		"press":     "press",
+		"hydralic":  "hydraulic",
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		want    string
+		lookups []string
+	}{
+		{"corrects a typo", "прес", "пресс", []string{"прес"}},
+		{"keeps the spelling of known words", "Станок прес", "Станок пресс", []string{"станок", "прес"}},
+		{"keeps capitals of a replaced word", "Hydralic PRESS", "Hydraulic PRESS", []string{"hydralic", "press"}},
+		{"capital query", "HYDRALIC press", "HYDRAULIC press", []string{"hydralic", "press"}},
+		{"keeps punctuation", "прес, гидравлик!", "пресс, гидравлический!", []string{"прес", "гидравлик"}},
+		{"nothing to correct", "Станок", "", []string{"станок"}},
+		{"unknown word", "xyzzy", "", []string{"xyzzy"}},
+		{"skips short words and numbers", "на H200 2024", "", nil},
+		{"looks up a repeated word once", "прес прес", "пресс пресс", []string{"прес"}},
+		{
+			"caps the words looked up",
+			"one two three four five six seven eight nine ten",
+			"",
+			[]string{"one", "two", "three", "four", "five", "six", "seven", "eight"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{closest: closest}
+			i := &Instance{lts: store}
+
+			got, err := i.didYouMean("ru", tt.query)
+			if err != nil {
+				t.Fatalf("didYouMean(%q): %v", tt.query, err)
+			}
+
+			if got != tt.want {
+				t.Errorf("didYouMean(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+
+			if tt.lookups == nil {
+				if len(store.lookups) != 0 {
+					t.Errorf("didYouMean(%q) looked up %v, want no lookup", tt.query, store.lookups)
+				}
+
+				return
+			}
+
+			if len(store.lookups) != 1 || !slices.Equal(store.lookups[0], tt.lookups) {
+				t.Errorf("didYouMean(%q) looked up %v, want one lookup of %v", tt.query, store.lookups, tt.lookups)
+			}
+		})
+	}
+}
+
+func TestMatchCase(t *testing.T) {
+	tests := []struct {
+		word        string
+		replacement string
+		want        string
+	}{
+		{"прес", "пресс", "пресс"},
+		{"Прес", "пресс", "Пресс"},
+		{"ПРЕС", "пресс", "ПРЕСС"},
+		{"hYdralic", "hydraulic", "hydraulic"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			if got := matchCase(tt.word, tt.replacement); got != tt.want {
+				t.Errorf("matchCase(%q, %q) = %q, want %q", tt.word, tt.replacement, got, tt.want)
+			}
+		})
+	}
+}
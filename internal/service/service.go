@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"html/template"
 	"international_site/internal/config"
 	"international_site/internal/logger"
@@ -11,28 +12,61 @@ import (
 )
 
 type Protocol interface {
-	GetPageBySlug(slug, locale string) (*models.Page, error)
+	GetPageBySlug(slug, locale, preview string) (*models.Page, error)
 	GetProductCategories(locale string) ([]models.ProductCategory, error)
 	GetCategoryBySlug(slug, locale string) (*models.ProductCategory, error)
 	GetCategoryByID(locale string, id uint) (*models.ProductCategory, error)
+	GetCategoryTree(locale string) ([]types.CategoryDTO, error)
 	GetProducts(locale string, offset, limit int) ([]models.Product, int, error)
-	GetProductsByCategory(locale string, categoryID uint, offset, limit int) ([]models.Product, int, error)
+	GetProductsByCategory(locale string, categoryID uint, includeDescendants bool, offset, limit int) ([]models.Product, int, error)
+	GetProductsAfter(locale, cursor string, limit int) ([]models.Product, string, error)
+	GetProductsByCategoryAfter(locale string, categoryID uint, includeDescendants bool, cursor string, limit int) ([]models.Product, string, error)
 	GetProductByID(locale string, id uint) (*models.Product, error)
-	GetRelatedProducts(locale string, categoryID, excludeID uint, limit int) ([]models.Product, error)
+	GetProductDetail(locale string, id uint, preview string) (*types.ProductDetail, error)
+	GetBreadcrumbs(locale, itemType string, id uint) ([]types.Breadcrumb, error)
+	GetRelatedProducts(locale string, productID uint, limit int) ([]types.RelatedProduct, error)
+	CompareProducts(locale string, ids []uint) (*types.ProductComparison, error)
+	GetProductDatasheet(locale string, id uint) (*types.Datasheet, error)
 	GetNews(locale string, offset, limit int) ([]models.News, int, error)
-	GetNewsByID(locale string, id uint) (*models.News, error)
+	GetNewsAfter(locale, cursor string, limit int) ([]models.News, string, error)
+	GetNewsByID(locale string, id uint, preview string) (*models.News, error)
 	GetRecentNews(locale string, limit int) ([]models.News, error)
 	GetDocumentsByType(docType, locale string) ([]models.Document, error)
 	GetContactsByType(contactType, locale string) ([]models.Contact, error)
 	SearchProducts(locale, query string, offset, limit int) ([]models.Product, int, error)
-	SearchAll(locale, query string) ([]types.SearchResult, error)
-	SearchAPI(locale, query string, limit int) ([]types.SearchResult, error)
-	FilterProducts(locale string, categoryID uint, search, sortBy, sortOrder string, offset, limit int) ([]models.Product, int, error)
+	Search(locale, query, itemType string, offset, limit int) (*types.SearchResponse, error)
+	SearchAfter(locale, query, itemType, cursor string, limit int) (*types.SearchResponse, error)
+	Suggest(locale, query string, limit int) (*types.Suggestions, error)
+	FilterProducts(filter types.ProductFilter) ([]models.Product, int, error)
+	FilterProductsAfter(filter types.ProductFilter, cursor string) ([]models.Product, string, error)
+	GetSpecFacets(filter types.ProductFilter) ([]types.SpecFacet, error)
 	GetProductsSorted(locale, search, sortBy, sortOrder string, offset, limit int) ([]models.Product, int, error)
 	SaveFeedback(feedback types.FeedbackRequest) (uint, error)
 	GetTranslation(key, locale string) string
 	GenerateSitemap(locale string) (string, error)
 	GetAvailableLanguages() []string
+	CreateProduct(req types.ProductRequest, author string) (uint, error)
+	UpdateProduct(id uint, req types.ProductRequest, author string) error
+	DeleteProduct(id uint, author string) error
+	SetProductOptions(productID uint, req types.ProductOptionsRequest, author string) error
+	SetStatus(itemType string, id uint, status, author string) error
+	CreatePreview(locale, itemType string, id uint) (*types.Preview, error)
+	SetNewsSchedule(id uint, req types.NewsScheduleRequest, author string) error
+	RunNewsScheduler(ctx context.Context)
+	RunVocabularyRefresh(ctx context.Context)
+	UpdatePage(id uint, req types.PageRequest, author string) error
+	UpdateNews(id uint, req types.NewsRequest, author string) error
+	GetRevisions(itemType string, id uint) ([]models.Revision, error)
+	GetRevision(id uint) (*models.Revision, error)
+	CompareRevisions(fromID, toID uint) (*types.RevisionComparison, error)
+	RestoreRevision(id uint, author string) (*models.Revision, error)
+	CreateCategory(req types.CategoryRequest) (uint, error)
+	UpdateCategory(id uint, req types.CategoryRequest) error
+	DeleteCategory(id uint) error
+	UpdateStock(req types.StockUpdateRequest) (int, error)
+	GetProductStock(productID uint) ([]models.ProductStock, error)
+	ImportCatalog(rows [][]string, dryRun bool, author string) (*types.ImportReport, error)
+	ExportCatalog() ([][]string, error)
 }
 
 type Instance struct {
@@ -41,6 +75,8 @@ type Instance struct {
 	cfg       *config.Service
 	NowFunc   func() time.Time
 	templates map[string]*template.Template
+
+	datasheets *datasheetCache
 }
 
 func New(
@@ -54,5 +90,7 @@ func New(
 		lts:     lts,
 		cfg:     cfg,
 		NowFunc: now,
+
+		datasheets: newDatasheetCache(datasheetCacheSize),
 	}
 }
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"international_site/internal/storage/lts"
+	"international_site/internal/storage/models"
+)
+
+// fakeStore answers the storage calls of the helpers under test; any other
+// call panics on the nil embedded Protocol.
+type fakeStore struct {
+	lts.Protocol
+
+	closest map[string]string
+	lookups [][]string
+}
+
+func (s *fakeStore) GetClosestWords(locale string, words []string) (map[string]string, error) {
+	s.lookups = append(s.lookups, words)
+
+	closest := make(map[string]string)
+	for _, word := range words {
+		if match, ok := s.closest[word]; ok {
+			closest[word] = match
+		}
+	}
+
+	return closest, nil
+}
+
+func (s *fakeStore) GetProductByID(id uint, locale string) (*models.Product, error) {
+	return &models.Product{ID: id}, nil
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"international_site/internal/storage/lts"
+	"international_site/internal/storage/models"
+	"international_site/internal/types"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Content item types shared by breadcrumbs, publication statuses and previews.
+const (
+	ItemProduct  = "product"
+	ItemCategory = "category"
+	ItemNews     = "news"
+	ItemPage     = "page"
+	ItemDocument = "document"
+)
+
+// previewTTL is how long a preview link stays valid.
+const previewTTL = 7 * 24 * time.Hour
+
+var publicationStatuses = []string{
+	models.StatusDraft,
+	models.StatusInReview,
+	models.StatusPublished,
+	models.StatusArchived,
+}
+
+// statusItems builds the model SetStatus updates for each item type.
+var statusItems = map[string]func(id uint) any{
+	ItemProduct:  func(id uint) any { return &models.Product{ID: id} },
+	ItemPage:     func(id uint) any { return &models.Page{ID: id} },
+	ItemNews:     func(id uint) any { return &models.News{ID: id} },
+	ItemDocument: func(id uint) any { return &models.Document{ID: id} },
+}
+
+// SetStatus moves a product, page, news item or document through the
+// publication lifecycle. Only published items are shown on the public site.
+func (i *Instance) SetStatus(itemType string, id uint, status, author string) error {
+	item, ok := statusItems[itemType]
+	if !ok {
+		return &ValidationError{Problems: []string{fmt.Sprintf("type: %q has no publication status", itemType)}}
+	}
+
+	if !slices.Contains(publicationStatuses, status) {
+		return &ValidationError{Problems: []string{
+			fmt.Sprintf("status: %q must be one of %s", status, strings.Join(publicationStatuses, ", ")),
+		}}
+	}
+
+	if itemType == ItemDocument {
+		return i.lts.SetStatus(item(id), status)
+	}
+
+	err := i.tracked(itemType, id, author, func(store lts.Protocol) error {
+		return store.SetStatus(item(id), status)
+	})
+
+	if err == nil && itemType == ItemProduct {
+		i.refreshVocabulary()
+	}
+
+	return err
+}
+
+// CreatePreview signs a link that shows a product, page or news item on the
+// public site whatever its status. An empty locale means the default language.
+func (i *Instance) CreatePreview(locale, itemType string, id uint) (*types.Preview, error) {
+	if i.cfg.PreviewSecret == "" {
+		return nil, errors.New("preview secret is not configured")
+	}
+
+	if locale == "" {
+		locale = i.cfg.DefaultLang
+	}
+
+	var path string
+
+	switch itemType {
+	case ItemProduct:
+		if _, err := i.lts.GetProductByID(id, locale); err != nil {
+			return nil, err
+		}
+
+		path = fmt.Sprintf("product/%d", id)
+
+	case ItemNews:
+		if _, err := i.lts.GetNewsByID(id, locale); err != nil {
+			return nil, err
+		}
+
+		path = fmt.Sprintf("news/%d", id)
+
+	case ItemPage:
+		page, err := i.lts.GetPageByID(id, locale)
+		if err != nil {
+			return nil, err
+		}
+
+		path = "page/" + page.Slug
+
+	default:
+		return nil, &ValidationError{Problems: []string{
+			fmt.Sprintf("type: %q must be one of product, news, page", itemType),
+		}}
+	}
+
+	expiresAt := i.NowFunc().Add(previewTTL).Truncate(time.Second)
+	payload := fmt.Sprintf("%s:%d:%d", itemType, id, expiresAt.Unix())
+	token := base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+		base64.RawURLEncoding.EncodeToString(i.previewSignature(payload))
+
+	return &types.Preview{
+		Token:     token,
+		URL:       i.localeURL(locale, path) + "?preview=" + token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// validPreview reports whether token is an unexpired preview of the given item.
+func (i *Instance) validPreview(token, itemType string, id uint) bool {
+	if token == "" || i.cfg.PreviewSecret == "" {
+		return false
+	}
+
+	encodedPayload, encodedSignature, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return false
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, i.previewSignature(string(payload))) {
+		return false
+	}
+
+	parts := strings.Split(string(payload), ":")
+	if len(parts) != 3 || parts[0] != itemType || parts[1] != strconv.FormatUint(uint64(id), 10) {
+		return false
+	}
+
+	expiresAt, err := strconv.ParseInt(parts[2], 10, 64)
+
+	return err == nil && i.NowFunc().Unix() < expiresAt
+}
+
+func (i *Instance) previewSignature(payload string) []byte {
+	mac := hmac.New(sha256.New, []byte(i.cfg.PreviewSecret))
+	mac.Write([]byte(payload))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"international_site/internal/config"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPreviewToken(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	i := &Instance{
+		lts:     &fakeStore{},
+		cfg:     &config.Service{DefaultLang: "ru", SiteURL: "https://example.com", PreviewSecret: "secret"},
+		NowFunc: func() time.Time { return now },
+	}
+
+	preview, err := i.CreatePreview("", ItemProduct, 12)
+	if err != nil {
+		t.Fatalf("CreatePreview: %v", err)
+	}
+
+	if want := "https://example.com/ru/product/12?preview=" + preview.Token; preview.URL != want {
+		t.Errorf("URL = %q, want %q", preview.URL, want)
+	}
+
+	if want := now.Add(previewTTL); !preview.ExpiresAt.Equal(want) {
+		t.Errorf("ExpiresAt = %v, want %v", preview.ExpiresAt, want)
+	}
+
+	payload, signature, _ := strings.Cut(preview.Token, ".")
+
+	tests := []struct {
+		name     string
+		token    string
+		itemType string
+		id       uint
+		secret   string
+		at       time.Time
+		want     bool
+	}{
+		{"valid", preview.Token, ItemProduct, 12, "secret", now, true},
+		{"just before expiry", preview.Token, ItemProduct, 12, "secret", now.Add(previewTTL - time.Second), true},
+		{"expired", preview.Token, ItemProduct, 12, "secret", now.Add(previewTTL), false},
+		{"other item", preview.Token, ItemProduct, 13, "secret", now, false},
+		{"other type", preview.Token, ItemNews, 12, "secret", now, false},
+		{"other secret", preview.Token, ItemProduct, 12, "rotated", now, false},
+		{"no secret", preview.Token, ItemProduct, 12, "", now, false},
+		{"tampered payload", "cHJvZHVjdDoxMjo5OTk5OTk5OTk5." + signature, ItemProduct, 12, "secret", now, false},
+		{"no signature", payload, ItemProduct, 12, "secret", now, false},
+		{"empty", "", ItemProduct, 12, "secret", now, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i.cfg.PreviewSecret = tt.secret
+			i.NowFunc = func() time.Time { return tt.at }
+
+			if got := i.validPreview(tt.token, tt.itemType, tt.id); got != tt.want {
+				t.Errorf("validPreview(%q, %q, %d) = %v, want %v", tt.token, tt.itemType, tt.id, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"fmt"
+	"international_site/internal/storage/models"
+	"international_site/internal/types"
+)
+
+// UpdateStock upserts stock levels in bulk and returns the number of rows written.
+func (i *Instance) UpdateStock(req types.StockUpdateRequest) (int, error) {
+	warehouses, err := i.lts.GetWarehouses()
+	if err != nil {
+		return 0, err
+	}
+
+	warehouseIDs := make(map[string]uint, len(warehouses))
+	for _, warehouse := range warehouses {
+		warehouseIDs[warehouse.Code] = warehouse.ID
+	}
+
+	var skus []string
+	for _, item := range req.Items {
+		if item.ProductID == 0 && item.SKU != "" {
+			skus = append(skus, item.SKU)
+		}
+	}
+
+	productIDs := map[string]uint{}
+	if len(skus) > 0 {
+		if productIDs, err = i.lts.GetProductIDsBySKU(skus); err != nil {
+			return 0, err
+		}
+	}
+
+	var problems []string
+
+	stock := make([]models.ProductStock, 0, len(req.Items))
+	seen := make(map[[2]uint]bool, len(req.Items))
+
+	for idx, item := range req.Items {
+		productID := item.ProductID
+		if productID == 0 {
+			productID = productIDs[item.SKU]
+		}
+
+		warehouseID, ok := warehouseIDs[item.Warehouse]
+
+		switch {
+		case productID == 0:
+			problems = append(problems, fmt.Sprintf("items[%d]: unknown product (product_id %d, sku %q)", idx, item.ProductID, item.SKU))
+		case !ok:
+			problems = append(problems, fmt.Sprintf("items[%d]: unknown warehouse %q", idx, item.Warehouse))
+		case item.Reserved > item.Quantity:
+			problems = append(problems, fmt.Sprintf("items[%d]: reserved %d exceeds quantity %d", idx, item.Reserved, item.Quantity))
+		case seen[[2]uint{productID, warehouseID}]:
+			problems = append(problems, fmt.Sprintf("items[%d]: product %d is listed twice for warehouse %q", idx, productID, item.Warehouse))
+		}
+
+		seen[[2]uint{productID, warehouseID}] = true
+
+		stock = append(stock, models.ProductStock{
+			ProductID:   productID,
+			WarehouseID: warehouseID,
+			Quantity:    item.Quantity,
+			Reserved:    item.Reserved,
+		})
+	}
+
+	if len(problems) > 0 {
+		return 0, &ValidationError{Problems: problems}
+	}
+
+	if err := i.lts.UpsertStock(stock); err != nil {
+		return 0, err
+	}
+
+	return len(stock), nil
+}
+
+// stockLevels are the lower bounds StockLevel buckets the available quantity
+// into, largest first.
+var stockLevels = []int{50, 10, 5, 1}
+
+// stockLevel buckets an available quantity for the public API: "10+" for 12.
+func stockLevel(quantity int) string {
+	for _, level := range stockLevels {
+		if quantity >= level {
+			return fmt.Sprintf("%d+", level)
+		}
+	}
+
+	return ""
+}
+
+// GetProductStock returns the per-warehouse stock of a product for the admin API.
+func (i *Instance) GetProductStock(productID uint) ([]models.ProductStock, error) {
+	return i.lts.GetProductStock(productID)
+}
+
+// fillAvailability derives the availability status from stock and lead time.
+func fillAvailability(product *models.Product) {
+	product.AvailableQuantity = 0
+
+	for _, stock := range product.Stock {
+		product.AvailableQuantity += max(stock.Quantity-stock.Reserved, 0)
+	}
+
+	switch {
+	case product.AvailableQuantity > 0:
+		product.Availability = models.AvailabilityInStock
+	case product.LeadTimeDays > 0:
+		product.Availability = models.AvailabilityOnOrder
+	default:
+		product.Availability = models.AvailabilityOutOfStock
+	}
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"fmt"
+	"international_site/internal/storage/lts"
+	"international_site/internal/storage/models"
+	"international_site/internal/types"
+	"international_site/pkg/units"
+)
+
+// storageFilter maps filter to the storage filter, converting its range
+// bounds from the requested unit, or the locale's preferred one, to base units.
+func (i *Instance) storageFilter(filter types.ProductFilter) (lts.ProductFilter, error) {
+	resolved := lts.ProductFilter{
+		Locale:             filter.Locale,
+		CategoryID:         filter.CategoryID,
+		IncludeDescendants: filter.IncludeDescendants,
+		Search:             filter.Search,
+		InStock:            filter.InStock,
+		Specs:              filter.Specs,
+		SortBy:             filter.SortBy,
+		SortOrder:          filter.SortOrder,
+		Offset:             filter.Offset,
+		Limit:              filter.Limit,
+	}
+
+	if len(filter.SpecRanges) == 0 {
+		return resolved, nil
+	}
+
+	var problems []string
+
+	resolved.SpecRanges = make(map[string]lts.SpecRange, len(filter.SpecRanges))
+
+	for name, bounds := range filter.SpecRanges {
+		dimension, err := i.lts.GetSpecDimension(filter.Locale, name)
+		if err != nil {
+			return resolved, err
+		}
+
+		unit, ok := i.displayUnit(filter.Locale, units.Dimension(dimension))
+
+		if bounds.Unit != "" {
+			unit, ok = units.Lookup(bounds.Unit)
+
+			switch {
+			case !ok:
+				problems = append(problems, fmt.Sprintf("spec_unit[%s]: unknown unit %q", name, bounds.Unit))
+				continue
+			case dimension != "" && unit.Dimension != units.Dimension(dimension):
+				problems = append(problems, fmt.Sprintf("spec_unit[%s]: %s is not a unit of %s", name, unit.Symbol, dimension))
+				continue
+			}
+		}
+
+		// specs without a dimension are compared as stored
+		if ok {
+			bounds.Min = toBase(bounds.Min, unit)
+			bounds.Max = toBase(bounds.Max, unit)
+		}
+
+		resolved.SpecRanges[name] = lts.SpecRange{Min: bounds.Min, Max: bounds.Max, Dimension: dimension}
+	}
+
+	if len(problems) > 0 {
+		return resolved, &ValidationError{Problems: problems}
+	}
+
+	return resolved, nil
+}
+
+func toBase(value *float64, unit units.Unit) *float64 {
+	if value == nil {
+		return nil
+	}
+
+	converted := units.ToBase(*value, unit)
+
+	return &converted
+}
+
+// displayDigits is the number of significant digits display values keep.
+const displayDigits = 4
+
+// displayUnit returns the unit the locale displays the dimension in: the one
+// set in the display_units config, or the metric base unit.
+func (i *Instance) displayUnit(locale string, dimension units.Dimension) (units.Unit, bool) {
+	if symbol, ok := i.cfg.DisplayUnits[locale][string(dimension)]; ok {
+		if unit, ok := units.Lookup(symbol); ok && unit.Dimension == dimension {
+			return unit, true
+		}
+	}
+
+	return units.Base(dimension)
+}
+
+// displayValue converts a base-unit value to the locale's display unit.
+func (i *Instance) displayValue(locale string, dimension string, value float64) (float64, string, bool) {
+	unit, ok := i.displayUnit(locale, units.Dimension(dimension))
+	if !ok {
+		return 0, "", false
+	}
+
+	return units.Round(units.FromBase(value, unit), displayDigits), unit.Symbol, true
+}
+
+// localizeSpecs fills DisplayValue/DisplayUnit of numeric specs in the locale's display unit.
+func (i *Instance) localizeSpecs(locale string, specs []models.ProductSpec) {
+	for idx := range specs {
+		spec := &specs[idx]
+		if spec.NumericValue == nil {
+			continue
+		}
+
+		if value, symbol, ok := i.displayValue(locale, spec.Dimension, *spec.NumericValue); ok {
+			spec.DisplayValue, spec.DisplayUnit = &value, symbol
+		}
+	}
+}
+
+// specNumericValue takes the explicit numeric value of a spec, or parses the
+// first translated value that holds a single quantity, and returns it in base units.
+func specNumericValue(spec types.ProductSpecRequest) (*float64, units.Unit, bool) {
+	if spec.NumericValue != nil {
+		unit, ok := units.Lookup(spec.Unit)
+		if !ok {
+			return nil, units.Unit{}, false
+		}
+
+		value := units.ToBase(*spec.NumericValue, unit)
+
+		return &value, unit, true
+	}
+
+	for _, trans := range spec.Translations {
+		parsed, unit, err := units.Parse(trans.Value)
+		if err != nil {
+			continue
+		}
+
+		value := units.ToBase(parsed, unit)
+
+		return &value, unit, true
+	}
+
+	return nil, units.Unit{}, false
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"international_site/internal/config"
+	"testing"
+)
+
+func TestDisplayValue(t *testing.T) {
+	i := &Instance{cfg: &config.Service{
+		DisplayUnits: map[string]map[string]string{
+			"en": {"length": "in", "mass": "lb"},
+			"pl": {"length": "kg"},
+		},
+	}}
+
+	tests := []struct {
+		name      string
+		locale    string
+		dimension string
+		value     float64
+		want      float64
+		symbol    string
+		ok        bool
+	}{
+		{"metric by default", "ru", "length", 1200, 1200, "mm", true},
+		{"imperial when configured", "en", "length", 1000, 39.37, "in", true},
+		{"rounded to significant digits", "en", "mass", 4500, 9921, "lb", true},
+		{"unconfigured dimension stays metric", "en", "power", 15, 15, "kW", true},
+		{"unit of another dimension is ignored", "pl", "length", 25.4, 25.4, "mm", true},
+		{"unknown dimension", "ru", "volume", 10, 0, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, symbol, ok := i.displayValue(tt.locale, tt.dimension, tt.value)
+			if value != tt.want || symbol != tt.symbol || ok != tt.ok {
+				t.Errorf("displayValue(%q, %q, %v) = %v, %q, %v, want %v, %q, %v",
+					tt.locale, tt.dimension, tt.value, value, symbol, ok, tt.want, tt.symbol, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"international_site/internal/storage/lts"
+	"international_site/internal/storage/models"
+	"international_site/internal/types"
+	"international_site/pkg/slug"
+	"sort"
+)
+
+// SetProductOptions replaces the option axes a configurable product offers to its variants.
+func (i *Instance) SetProductOptions(productID uint, req types.ProductOptionsRequest, author string) error {
+	languages, err := i.lts.GetLanguages()
+	if err != nil {
+		return err
+	}
+
+	product, err := i.lts.GetProductByID(productID, i.cfg.DefaultLang)
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+
+	if product.ParentID != nil {
+		problems = append(problems, fmt.Sprintf("product %d is a variant; options belong to its parent %d", productID, *product.ParentID))
+	}
+
+	options := make([]models.ProductOption, 0, len(req.Options))
+	optionCodes := make(map[string]bool, len(req.Options))
+
+	for idx, optionReq := range req.Options {
+		field := fmt.Sprintf("options[%d]", idx)
+		problems = append(problems, checkOptionCode(field, optionReq.Code, optionCodes)...)
+		problems = append(problems, checkOptionTranslations(field+".translations", optionReq.Translations, languages)...)
+
+		if len(optionReq.Values) == 0 {
+			problems = append(problems, field+".values: at least one value is required")
+		}
+
+		option := models.ProductOption{Code: optionReq.Code, SortOrder: optionReq.SortOrder}
+
+		for _, trans := range optionReq.Translations {
+			option.Translations = append(option.Translations, models.ProductOptionTranslation{
+				LanguageCode: trans.LanguageCode,
+				Name:         trans.Name,
+			})
+		}
+
+		valueCodes := make(map[string]bool, len(optionReq.Values))
+
+		for j, valueReq := range optionReq.Values {
+			valueField := fmt.Sprintf("%s.values[%d]", field, j)
+			problems = append(problems, checkOptionCode(valueField, valueReq.Code, valueCodes)...)
+			problems = append(problems, checkOptionTranslations(valueField+".translations", valueReq.Translations, languages)...)
+
+			value := models.ProductOptionValue{Code: valueReq.Code, SortOrder: valueReq.SortOrder}
+
+			for _, trans := range valueReq.Translations {
+				value.Translations = append(value.Translations, models.ProductOptionValueTranslation{
+					LanguageCode: trans.LanguageCode,
+					Label:        trans.Name,
+				})
+			}
+
+			option.Values = append(option.Values, value)
+		}
+
+		options = append(options, option)
+	}
+
+	if len(product.Variants) > 0 {
+		problems = append(problems, checkVariantAxes(product, req.Options)...)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+
+	return i.tracked(ItemProduct, productID, author, func(store lts.Protocol) error {
+		return store.SetProductOptions(productID, options)
+	})
+}
+
+// checkVariantAxes keeps the axes of a product with variants fixed: every
+// variant selects one value per axis, so adding or removing an axis, or a value
+// a variant selects, would leave its selections incomplete or stale.
+func checkVariantAxes(product *models.Product, options []types.ProductOptionRequest) []string {
+	var problems []string
+
+	requested := make(map[string]map[string]bool, len(options))
+	for _, option := range options {
+		values := make(map[string]bool, len(option.Values))
+		for _, value := range option.Values {
+			values[value.Code] = true
+		}
+
+		requested[option.Code] = values
+	}
+
+	existing := make(map[string]bool, len(product.Options))
+	optionCodes := make(map[uint]string, len(product.Options))
+	valueCodes := make(map[uint]string)
+
+	for _, option := range product.Options {
+		existing[option.Code] = true
+		optionCodes[option.ID] = option.Code
+
+		for _, value := range option.Values {
+			valueCodes[value.ID] = value.Code
+		}
+
+		if _, ok := requested[option.Code]; !ok {
+			problems = append(problems, fmt.Sprintf("options: option %q cannot be removed while product %d has variants", option.Code, product.ID))
+		}
+	}
+
+	for _, option := range options {
+		if !existing[option.Code] {
+			problems = append(problems, fmt.Sprintf("options: option %q cannot be added while product %d has variants", option.Code, product.ID))
+		}
+	}
+
+	for _, variant := range product.Variants {
+		for _, selected := range variant.VariantValues {
+			optionCode, valueCode := optionCodes[selected.OptionID], valueCodes[selected.ValueID]
+
+			if values, ok := requested[optionCode]; ok && !values[valueCode] {
+				problems = append(problems, fmt.Sprintf("options[%s]: value %q is selected by variant %s", optionCode, valueCode, variant.SKU))
+			}
+		}
+	}
+
+	return problems
+}
+
+func checkOptionCode(field, code string, seen map[string]bool) []string {
+	var problems []string
+
+	switch {
+	case !slug.Valid(code):
+		problems = append(problems, fmt.Sprintf("%s.code: %q may contain only a-z, 0-9 and single dashes", field, code))
+	case seen[code]:
+		problems = append(problems, fmt.Sprintf("%s.code: duplicate code %q", field, code))
+	}
+
+	seen[code] = true
+
+	return problems
+}
+
+func checkOptionTranslations(field string, translations []types.OptionTranslationRequest, languages []models.Language) []string {
+	codes := make([]string, 0, len(translations))
+	for _, trans := range translations {
+		codes = append(codes, trans.LanguageCode)
+	}
+
+	return checkLanguageCoverage(field, codes, languages)
+}
+
+// variantValues checks the parent of a variant request and resolves its option
+// codes into value ids. It returns the parent, or nil for a standalone product.
+func (i *Instance) variantValues(id uint, req types.ProductRequest) (*models.Product, []models.ProductVariantValue, []string, error) {
+	if req.ParentID == nil {
+		if len(req.OptionValues) > 0 {
+			return nil, nil, []string{"option_values: only variants with a parent_id select option values"}, nil
+		}
+
+		return nil, nil, nil, nil
+	}
+
+	if *req.ParentID == id {
+		return nil, nil, []string{"parent_id: product cannot be its own parent"}, nil
+	}
+
+	parent, err := i.lts.GetProductByID(*req.ParentID, i.cfg.DefaultLang)
+
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return nil, nil, []string{fmt.Sprintf("parent_id: product %d does not exist", *req.ParentID)}, nil
+	case err != nil:
+		return nil, nil, nil, err
+	case parent.ParentID != nil:
+		return nil, nil, []string{fmt.Sprintf("parent_id: product %d is itself a variant", *req.ParentID)}, nil
+	case len(parent.Options) == 0:
+		return nil, nil, []string{fmt.Sprintf("parent_id: product %d has no options", *req.ParentID)}, nil
+	}
+
+	var problems []string
+
+	values := make([]models.ProductVariantValue, 0, len(parent.Options))
+	known := make(map[string]bool, len(parent.Options))
+
+	for _, option := range parent.Options {
+		known[option.Code] = true
+
+		code, ok := req.OptionValues[option.Code]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("option_values: missing value for option %q", option.Code))
+			continue
+		}
+
+		var valueID uint
+		for _, value := range option.Values {
+			if value.Code == code {
+				valueID = value.ID
+			}
+		}
+
+		if valueID == 0 {
+			problems = append(problems, fmt.Sprintf("option_values[%s]: unknown value %q", option.Code, code))
+			continue
+		}
+
+		values = append(values, models.ProductVariantValue{OptionID: option.ID, ValueID: valueID})
+	}
+
+	var unknown []string
+	for code := range req.OptionValues {
+		if !known[code] {
+			unknown = append(unknown, code)
+		}
+	}
+
+	sort.Strings(unknown)
+
+	for _, code := range unknown {
+		problems = append(problems, fmt.Sprintf("option_values: product %d has no option %q", *req.ParentID, code))
+	}
+
+	if len(problems) > 0 {
+		return nil, nil, problems, nil
+	}
+
+	for _, variant := range parent.Variants {
+		if variant.ID != id && sameVariantValues(variant.VariantValues, values) {
+			problems = append(problems, fmt.Sprintf("option_values: variant %s already has this combination", variant.SKU))
+		}
+	}
+
+	return parent, values, problems, nil
+}
+
+func sameVariantValues(a, b []models.ProductVariantValue) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	chosen := make(map[uint]uint, len(a))
+	for _, value := range a {
+		chosen[value.OptionID] = value.ValueID
+	}
+
+	for _, value := range b {
+		if valueID, ok := chosen[value.OptionID]; !ok || valueID != value.ValueID {
+			return false
+		}
+	}
+
+	return true
+}
+
+// prepareVariants gives every variant the parent specs it does not override,
+// matched by spec name, and rolls variant stock up into the parent.
+func (i *Instance) prepareVariants(locale string, product *models.Product) {
+	if len(product.Variants) == 0 {
+		return
+	}
+
+	for idx := range product.Variants {
+		variant := &product.Variants[idx]
+		variant.Specs = inheritSpecs(product.Specs, variant.Specs)
+		i.prepareProduct(locale, variant)
+
+		product.AvailableQuantity += variant.AvailableQuantity
+	}
+
+	switch {
+	case product.AvailableQuantity > 0:
+		product.Availability = models.AvailabilityInStock
+	case product.Availability == models.AvailabilityOutOfStock:
+		for _, variant := range product.Variants {
+			if variant.Availability == models.AvailabilityOnOrder {
+				product.Availability = models.AvailabilityOnOrder
+			}
+		}
+	}
+}
+
+func inheritSpecs(parentSpecs, overrides []models.ProductSpec) []models.ProductSpec {
+	byName := make(map[string]int, len(overrides))
+	for idx, spec := range overrides {
+		byName[specName(spec)] = idx
+	}
+
+	specs := make([]models.ProductSpec, 0, len(parentSpecs)+len(overrides))
+	used := make(map[int]bool, len(overrides))
+
+	for _, spec := range parentSpecs {
+		if idx, ok := byName[specName(spec)]; ok {
+			specs = append(specs, overrides[idx])
+			used[idx] = true
+			continue
+		}
+
+		specs = append(specs, spec)
+	}
+
+	for idx, spec := range overrides {
+		if !used[idx] {
+			specs = append(specs, spec)
+		}
+	}
+
+	return specs
+}
+
+func specName(spec models.ProductSpec) string {
+	if len(spec.Translations) == 0 {
+		return ""
+	}
+
+	return spec.Translations[0].Name
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"international_site/internal/storage/models"
+	"international_site/internal/types"
+	"reflect"
+	"testing"
+)
+
+func TestCheckVariantAxes(t *testing.T) {
+	product := &models.Product{
+		ID: 7,
+		Options: []models.ProductOption{
+			{ID: 1, Code: "capacity", Values: []models.ProductOptionValue{{ID: 11, Code: "1t"}, {ID: 12, Code: "2t"}}},
+			{ID: 2, Code: "voltage", Values: []models.ProductOptionValue{{ID: 21, Code: "230v"}, {ID: 22, Code: "400v"}}},
+		},
+		Variants: []models.Product{
+			{SKU: "HP-1T-230", VariantValues: []models.ProductVariantValue{{OptionID: 1, ValueID: 11}, {OptionID: 2, ValueID: 21}}},
+			{SKU: "HP-2T-400", VariantValues: []models.ProductVariantValue{{OptionID: 1, ValueID: 12}, {OptionID: 2, ValueID: 22}}},
+		},
+	}
+
+	option := func(code string, values ...string) types.ProductOptionRequest {
+		option := types.ProductOptionRequest{Code: code}
+		for _, value := range values {
+			option.Values = append(option.Values, types.OptionValueRequest{Code: value})
+		}
+
+		return option
+	}
+
+	tests := []struct {
+		name    string
+		options []types.ProductOptionRequest
+		want    []string
+	}{
+		{
+			name:    "same axes with a new value",
+			options: []types.ProductOptionRequest{option("capacity", "1t", "2t", "5t"), option("voltage", "230v", "400v")},
+		},
+		{
+			name:    "unused value removed",
+			options: []types.ProductOptionRequest{option("capacity", "1t", "2t"), option("voltage", "230v", "400v")},
+		},
+		{
+			name:    "axis added",
+			options: []types.ProductOptionRequest{option("capacity", "1t", "2t"), option("voltage", "230v", "400v"), option("colour", "red")},
+			want:    []string{`options: option "colour" cannot be added while product 7 has variants`},
+		},
+		{
+			name:    "axis removed",
+			options: []types.ProductOptionRequest{option("capacity", "1t", "2t")},
+			want:    []string{`options: option "voltage" cannot be removed while product 7 has variants`},
+		},
+		{
+			name:    "selected value removed",
+			options: []types.ProductOptionRequest{option("capacity", "1t"), option("voltage", "230v", "400v")},
+			want:    []string{`options[capacity]: value "2t" is selected by variant HP-2T-400`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkVariantAxes(product, tt.options); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("checkVariantAxes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package lts
+
+import (
+	"errors"
+	"fmt"
+	"international_site/internal/storage/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrNotFound is returned when a write targets a row that does not exist.
+	ErrNotFound = errors.New("record not found")
+	// ErrConflict is returned when a write violates a unique or foreign key constraint.
+	ErrConflict = errors.New("conflicting record")
+)
+
+func translateError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey), errors.Is(err, gorm.ErrForeignKeyViolated):
+		return fmt.Errorf("%w: %v", ErrConflict, err)
+	}
+
+	return err
+}
+
+func (i *Instance) GetLanguages() ([]models.Language, error) {
+	var languages []models.Language
+
+	err := i.db.
+		Debug().
+		Order("code ASC").
+		Find(&languages).Error
+
+	return languages, err
+}
+
+// CreateProduct inserts the product together with its translations, specs, prices, media, links and variant values in one transaction.
+func (i *Instance) CreateProduct(product *models.Product) error {
+	err := i.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Debug().Omit(clause.Associations).Create(product).Error; err != nil {
+			return err
+		}
+
+		return createProductChildren(tx, product)
+	})
+
+	return translateError(err)
+}
+
+// UpdateProduct overwrites the product row and replaces all of its translations, specs, prices, media, links and variant values.
+func (i *Instance) UpdateProduct(product *models.Product) error {
+	err := i.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Debug().
+			Model(product).
+			Select("category_id", "parent_id", "sku", "image_url", "file_url", "sort_order", "price_on_request", "lead_time_days", "updated_at").
+			Updates(product)
+
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		if err := tx.Debug().Where("product_id = ?", product.ID).Delete(&models.ProductTranslation{}).Error; err != nil {
+			return err
+		}
+
+		// spec translations are removed by ON DELETE CASCADE
+		if err := tx.Debug().Where("product_id = ?", product.ID).Delete(&models.ProductSpec{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Debug().Where("product_id = ?", product.ID).Delete(&models.ProductPrice{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Debug().Where("product_id = ?", product.ID).Delete(&models.ProductVariantValue{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Debug().Where("product_id = ?", product.ID).Delete(&models.ProductLink{}).Error; err != nil {
+			return err
+		}
+
+		// media translations are removed by ON DELETE CASCADE
+		if err := tx.Debug().Where("product_id = ?", product.ID).Delete(&models.ProductMedia{}).Error; err != nil {
+			return err
+		}
+
+		return createProductChildren(tx, product)
+	})
+
+	return translateError(err)
+}
+
+func (i *Instance) DeleteProduct(id uint) error {
+	res := i.db.Debug().Delete(&models.Product{}, id)
+
+	if res.Error != nil {
+		return translateError(res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func createProductChildren(tx *gorm.DB, product *models.Product) error {
+	for idx := range product.Translations {
+		product.Translations[idx].ProductID = product.ID
+	}
+
+	if len(product.Translations) > 0 {
+		if err := tx.Debug().Create(&product.Translations).Error; err != nil {
+			return err
+		}
+	}
+
+	for idx := range product.Specs {
+		spec := &product.Specs[idx]
+		spec.ProductID = product.ID
+
+		if err := tx.Debug().Omit(clause.Associations).Create(spec).Error; err != nil {
+			return err
+		}
+
+		for j := range spec.Translations {
+			spec.Translations[j].SpecID = spec.ID
+		}
+
+		if len(spec.Translations) > 0 {
+			if err := tx.Debug().Create(&spec.Translations).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	for idx := range product.Prices {
+		product.Prices[idx].ProductID = product.ID
+	}
+
+	if len(product.Prices) > 0 {
+		if err := tx.Debug().Create(&product.Prices).Error; err != nil {
+			return err
+		}
+	}
+
+	for idx := range product.Media {
+		media := &product.Media[idx]
+		media.ProductID = product.ID
+
+		if err := tx.Debug().Omit(clause.Associations).Create(media).Error; err != nil {
+			return err
+		}
+
+		for j := range media.Translations {
+			media.Translations[j].MediaID = media.ID
+		}
+
+		if len(media.Translations) > 0 {
+			if err := tx.Debug().Create(&media.Translations).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	for idx := range product.Links {
+		product.Links[idx].ProductID = product.ID
+	}
+
+	if len(product.Links) > 0 {
+		if err := tx.Debug().Create(&product.Links).Error; err != nil {
+			return err
+		}
+	}
+
+	for idx := range product.VariantValues {
+		product.VariantValues[idx].ProductID = product.ID
+	}
+
+	if len(product.VariantValues) > 0 {
+		if err := tx.Debug().Create(&product.VariantValues).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (i *Instance) CategorySlugExists(slug, locale string, excludeID uint) (bool, error) {
+	var count int64
+
+	err := i.db.Model(&models.ProductCategoryTranslation{}).
+		Debug().
+		Where("language_code = ? AND slug = ? AND category_id <> ?", locale, slug, excludeID).
+		Count(&count).Error
+
+	return count > 0, err
+}
+
+// CreateCategory inserts the category together with its translations in one transaction.
+func (i *Instance) CreateCategory(category *models.ProductCategory) error {
+	err := i.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Debug().Omit(clause.Associations).Create(category).Error; err != nil {
+			return err
+		}
+
+		return createCategoryTranslations(tx, category)
+	})
+
+	return translateError(err)
+}
+
+// UpdateCategory overwrites the category row and replaces all of its translations.
+func (i *Instance) UpdateCategory(category *models.ProductCategory) error {
+	err := i.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Debug().
+			Model(category).
+			Select("parent_id", "sort_order").
+			Updates(category)
+
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		if err := tx.Debug().Where("category_id = ?", category.ID).Delete(&models.ProductCategoryTranslation{}).Error; err != nil {
+			return err
+		}
+
+		return createCategoryTranslations(tx, category)
+	})
+
+	return translateError(err)
+}
+
+// DeleteCategory fails with ErrConflict while the category still has products or children.
+func (i *Instance) DeleteCategory(id uint) error {
+	res := i.db.Debug().Delete(&models.ProductCategory{}, id)
+
+	if res.Error != nil {
+		return translateError(res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func createCategoryTranslations(tx *gorm.DB, category *models.ProductCategory) error {
+	for idx := range category.Translations {
+		category.Translations[idx].CategoryID = category.ID
+	}
+
+	if len(category.Translations) == 0 {
+		return nil
+	}
+
+	return tx.Debug().Create(&category.Translations).Error
+}
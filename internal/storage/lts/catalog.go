@@ -0,0 +1,76 @@
+package lts
+
+import (
+	"international_site/internal/storage/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetCatalog returns every product, variants included, with translations and
+// specs in all languages, ordered by SKU.
+func (i *Instance) GetCatalog() ([]models.Product, error) {
+	var products []models.Product
+
+	err := i.db.
+		Debug().
+		Preload("Translations").
+		Preload("Specs", byOrder).
+		Preload("Specs.Translations").
+		Order("sku ASC").
+		Find(&products).Error
+
+	return products, err
+}
+
+// ImportProducts creates products without an ID and updates the rest in one
+// transaction. Only the product row, translations and specs are written;
+// prices, media, links, stock and variant values are left as they are.
+func (i *Instance) ImportProducts(products []models.Product) error {
+	err := i.db.Transaction(func(tx *gorm.DB) error {
+		for idx := range products {
+			product := &products[idx]
+
+			if product.ID == 0 {
+				if err := tx.Debug().Omit(clause.Associations).Create(product).Error; err != nil {
+					return err
+				}
+			} else {
+				res := tx.Debug().
+					Model(product).
+					Select("category_id", "sku", "image_url", "file_url", "sort_order", "price_on_request", "lead_time_days", "updated_at").
+					Updates(product)
+
+				if res.Error != nil {
+					return res.Error
+				}
+
+				if res.RowsAffected == 0 {
+					return ErrNotFound
+				}
+
+				if err := tx.Debug().Where("product_id = ?", product.ID).Delete(&models.ProductTranslation{}).Error; err != nil {
+					return err
+				}
+
+				// spec translations are removed by ON DELETE CASCADE
+				if err := tx.Debug().Where("product_id = ?", product.ID).Delete(&models.ProductSpec{}).Error; err != nil {
+					return err
+				}
+			}
+
+			for j := range product.Specs {
+				product.Specs[j].ID = 0
+			}
+
+			imported := models.Product{ID: product.ID, Translations: product.Translations, Specs: product.Specs}
+			if err := createProductChildren(tx, &imported); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return translateError(err)
+}
@@ -0,0 +1,122 @@
+package lts
+
+import (
+	"gorm.io/gorm"
+)
+
+// categoryClosureCTE lists every (ancestor, descendant) pair of the category
+// tree, including each category paired with itself at depth 0. It must be
+// placed inside a WITH RECURSIVE clause.
+const categoryClosureCTE = `category_closure AS (
+	SELECT category_id AS ancestor_id, category_id AS descendant_id, 0 AS depth
+	FROM product_categories
+	UNION ALL
+	SELECT cc.ancestor_id, pc.category_id, cc.depth + 1
+	FROM category_closure cc
+	JOIN product_categories pc ON pc.parent_id = cc.descendant_id
+)`
+
+const categoryTreeQuery = `WITH RECURSIVE ` + categoryClosureCTE + `,
+direct_counts AS (
+	SELECT category_id, COUNT(*) AS product_count
+	FROM products
+	WHERE parent_id IS NULL AND status = 'published'
+	GROUP BY category_id
+),
+total_counts AS (
+	SELECT cc.ancestor_id AS category_id, SUM(dc.product_count) AS product_count
+	FROM category_closure cc
+	JOIN direct_counts dc ON dc.category_id = cc.descendant_id
+	GROUP BY cc.ancestor_id
+)
+SELECT
+	pc.category_id,
+	pc.parent_id,
+	pc.sort_order,
+	COALESCE(t.name, '') AS name,
+	COALESCE(t.description, '') AS description,
+	COALESCE(t.slug, '') AS slug,
+	COALESCE(dc.product_count, 0) AS product_count,
+	COALESCE(tc.product_count, 0) AS total_product_count
+FROM product_categories pc
+LEFT JOIN product_category_translations t ON t.category_id = pc.category_id AND t.language_code = ?
+LEFT JOIN direct_counts dc ON dc.category_id = pc.category_id
+LEFT JOIN total_counts tc ON tc.category_id = pc.category_id
+ORDER BY pc.sort_order ASC, pc.category_id ASC`
+
+const categorySubtreeQuery = `WITH RECURSIVE subtree AS (
+	SELECT category_id FROM product_categories WHERE category_id = ?
+	UNION ALL
+	SELECT pc.category_id
+	FROM product_categories pc
+	JOIN subtree s ON pc.parent_id = s.category_id
+)
+SELECT category_id FROM subtree`
+
+// inCategory restricts a products query to the category, or to the category
+// and all of its descendants when includeDescendants is set.
+func (i *Instance) inCategory(categoryID uint, includeDescendants bool) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if includeDescendants {
+			return db.Where("products.category_id IN (?)", i.db.Raw(categorySubtreeQuery, categoryID))
+		}
+
+		return db.Where("products.category_id = ?", categoryID)
+	}
+}
+
+const categoryAncestorsQuery = `WITH RECURSIVE ancestors AS (
+	SELECT category_id, parent_id, 0 AS depth FROM product_categories WHERE category_id = ?
+	UNION ALL
+	SELECT pc.category_id, pc.parent_id, a.depth + 1
+	FROM product_categories pc
+	JOIN ancestors a ON pc.category_id = a.parent_id
+)
+SELECT
+	a.category_id,
+	a.parent_id,
+	COALESCE(t.name, '') AS name,
+	COALESCE(t.slug, '') AS slug
+FROM ancestors a
+LEFT JOIN product_category_translations t ON t.category_id = a.category_id AND t.language_code = ?
+ORDER BY a.depth DESC`
+
+// CategoryRow is a category of the flat lists GetCategoryTree and
+// GetCategoryAncestors return; the service nests and maps them.
+type CategoryRow struct {
+	ID                uint `gorm:"column:category_id"`
+	ParentID          *uint
+	SortOrder         int
+	Name              string
+	Description       string
+	Slug              string
+	ProductCount      int
+	TotalProductCount int
+}
+
+// GetCategoryTree returns every category as a flat list ordered by sort_order,
+// with direct and descendant-inclusive product counts.
+func (i *Instance) GetCategoryTree(locale string) ([]CategoryRow, error) {
+	var rows []CategoryRow
+
+	err := i.db.Debug().Raw(categoryTreeQuery, locale).Scan(&rows).Error
+
+	return rows, err
+}
+
+// GetCategoryAncestors returns the path from the root category down to the
+// category itself, or ErrNotFound when the category does not exist. The rows
+// carry no product counts.
+func (i *Instance) GetCategoryAncestors(categoryID uint, locale string) ([]CategoryRow, error) {
+	var rows []CategoryRow
+
+	if err := i.db.Debug().Raw(categoryAncestorsQuery, categoryID, locale).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,66 @@
+package lts
+
+import (
+	"international_site/internal/storage/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ListCursor is the key of the last row of a keyset page: (sort_order,
+// created_at, id). The service hands it to clients as an opaque token.
+type ListCursor struct {
+	SortOrder int       `json:"s"`
+	CreatedAt time.Time `json:"c"`
+	ID        uint      `json:"i"`
+}
+
+// productOrder is the default product listing order. The id breaks ties, so
+// that offset and cursor pages list the products in the same order.
+const productOrder = "products.sort_order ASC, products.created_at DESC, products.product_id DESC"
+
+// productsAfter orders products by productOrder and keeps only the rows that
+// come after the cursor.
+func productsAfter(after *ListCursor) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Order(productOrder)
+
+		if after == nil || after.ID == 0 {
+			return db
+		}
+
+		return db.Where(
+			"products.sort_order > ? OR (products.sort_order = ? AND (products.created_at < ? OR (products.created_at = ? AND products.product_id < ?)))",
+			after.SortOrder, after.SortOrder, after.CreatedAt, after.CreatedAt, after.ID)
+	}
+}
+
+// newsAfter is productsAfter for news, which have no sort_order and are
+// dated by publish_at when it is set.
+func newsAfter(after *ListCursor) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Order(newsDateOrder)
+
+		if after == nil || after.ID == 0 {
+			return db
+		}
+
+		return db.Where(
+			newsDate+" < ? OR ("+newsDate+" = ? AND news.news_id < ?)",
+			after.CreatedAt, after.CreatedAt, after.ID)
+	}
+}
+
+// GetNewsAfter is GetNews with keyset pagination.
+func (i *Instance) GetNewsAfter(locale string, now time.Time, after *ListCursor, limit int) ([]models.News, error) {
+	var news []models.News
+
+	err := i.db.
+		Debug().
+		Preload("Translations", "language_code = ?", locale).
+		Scopes(liveNews(now), newsAfter(after)).
+		Limit(limit).
+		Find(&news).Error
+
+	return news, err
+}
@@ -0,0 +1,230 @@
+package lts
+
+import (
+	"international_site/internal/storage/models"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ProductFilter selects the products of FilterProducts and GetSpecFacets.
+// Specs maps a spec name to the accepted values: values of one name are
+// OR-ed, different names are AND-ed. SpecRanges bound numeric spec values in
+// base units. After switches to keyset pagination: Offset, SortBy and
+// SortOrder are ignored and no total is counted.
+type ProductFilter struct {
+	Locale             string
+	CategoryID         uint
+	IncludeDescendants bool
+	Search             string
+	InStock            bool
+	Specs              map[string][]string
+	SpecRanges         map[string]SpecRange
+	SortBy             string
+	SortOrder          string
+	Offset             int
+	Limit              int
+	After              *ListCursor
+}
+
+// SpecRange bounds a numeric spec in base units; a nil bound is open. Only
+// values recorded in Dimension match, "" standing for dimensionless values.
+type SpecRange struct {
+	Min       *float64
+	Max       *float64
+	Dimension string
+}
+
+// SpecFacet counts the products per value of a spec in one dimension; Min and
+// Max bound its numeric values in base units.
+type SpecFacet struct {
+	Name      string
+	Values    []FacetValue
+	Min       *float64
+	Max       *float64
+	Dimension string
+}
+
+type FacetValue struct {
+	Value string
+	Count int
+}
+
+// filterProducts applies every ProductFilter condition except sorting and paging.
+// Stock and spec conditions also match a parent through any of its variants.
+func (i *Instance) filterProducts(db *gorm.DB, filter ProductFilter) *gorm.DB {
+	db = published("products")(parentProducts(db))
+
+	if filter.CategoryID > 0 {
+		db = i.inCategory(filter.CategoryID, filter.IncludeDescendants)(db)
+	}
+
+	if filter.Search != "" {
+		subQuery := i.searchMatches(productSearch, filter.Locale, filter.Search).
+			Select("product_id")
+		db = db.Where("products.product_id IN (?) OR products.product_id IN (?)",
+			subQuery, i.variantParentsBySKU(strings.TrimSpace(filter.Search)))
+	}
+
+	if filter.InStock {
+		subQuery := i.db.Model(&models.ProductStock{}).
+			Select("product_id").
+			Where("quantity > reserved")
+		db = selfOrParentOf(db, subQuery)
+	}
+
+	// sorted so that equal filters always produce the same prepared statement
+	names := make([]string, 0, len(filter.Specs))
+	for name := range filter.Specs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		subQuery := i.db.Model(&models.ProductSpec{}).
+			Select("product_specs.product_id").
+			Joins("JOIN product_spec_translations pst ON pst.spec_id = product_specs.spec_id").
+			Where("pst.language_code = ? AND pst.name = ? AND pst.value IN ?", filter.Locale, name, filter.Specs[name])
+		db = selfOrParentOf(db, subQuery)
+	}
+
+	names = names[:0]
+	for name := range filter.SpecRanges {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		bounds := filter.SpecRanges[name]
+		subQuery := i.db.Model(&models.ProductSpec{}).
+			Select("product_specs.product_id").
+			Joins("JOIN product_spec_translations pst ON pst.spec_id = product_specs.spec_id").
+			Where("pst.language_code = ? AND pst.name = ? AND product_specs.numeric_value IS NOT NULL", filter.Locale, name).
+			Where("COALESCE(product_specs.dimension, '') = ?", bounds.Dimension)
+
+		if bounds.Min != nil {
+			subQuery = subQuery.Where("product_specs.numeric_value >= ?", *bounds.Min)
+		}
+
+		if bounds.Max != nil {
+			subQuery = subQuery.Where("product_specs.numeric_value <= ?", *bounds.Max)
+		}
+
+		db = selfOrParentOf(db, subQuery)
+	}
+
+	return db
+}
+
+// GetSpecDimension returns the dimension recorded for the spec name, or "" when its values are not numeric.
+// A name recorded in several dimensions resolves to the one most of its values use.
+func (i *Instance) GetSpecDimension(locale, name string) (string, error) {
+	var dimensions []string
+
+	err := i.db.Model(&models.ProductSpec{}).
+		Debug().
+		Joins("JOIN product_spec_translations pst ON pst.spec_id = product_specs.spec_id").
+		Where("pst.language_code = ? AND pst.name = ? AND product_specs.dimension <> ''", locale, name).
+		Group("product_specs.dimension").
+		Order("COUNT(*) DESC, product_specs.dimension ASC").
+		Limit(1).
+		Pluck("product_specs.dimension", &dimensions).Error
+
+	if err != nil || len(dimensions) == 0 {
+		return "", err
+	}
+
+	return dimensions[0], nil
+}
+
+type specFacetRow struct {
+	Name         string
+	Dimension    string
+	Value        string
+	ProductCount int
+}
+
+type specRangeRow struct {
+	Name      string
+	Dimension string
+	MinValue  float64
+	MaxValue  float64
+}
+
+// specKey identifies a facet: one spec name may be recorded in several dimensions.
+type specKey struct {
+	Name      string
+	Dimension string
+}
+
+// GetSpecFacets counts products per spec name, dimension and value among the products matching filter.
+func (i *Instance) GetSpecFacets(filter ProductFilter) ([]SpecFacet, error) {
+	var rows []specFacetRow
+
+	matching := i.filterProducts(i.db.Model(&models.Product{}).Select("products.product_id"), filter)
+
+	err := i.db.
+		Debug().
+		Table("product_specs ps").
+		Select("pst.name, COALESCE(ps.dimension, '') AS dimension, pst.value, COUNT(DISTINCT ps.product_id) AS product_count").
+		Joins("JOIN product_spec_translations pst ON pst.spec_id = ps.spec_id AND pst.language_code = ?", filter.Locale).
+		Where("ps.product_id IN (?)", matching).
+		Group("pst.name, COALESCE(ps.dimension, ''), pst.value").
+		Order("pst.name ASC, dimension ASC, product_count DESC, pst.value ASC").
+		Scan(&rows).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	var ranges []specRangeRow
+
+	err = i.db.
+		Debug().
+		Table("product_specs ps").
+		Select("pst.name, COALESCE(ps.dimension, '') AS dimension, MIN(ps.numeric_value) AS min_value, MAX(ps.numeric_value) AS max_value").
+		Joins("JOIN product_spec_translations pst ON pst.spec_id = ps.spec_id AND pst.language_code = ?", filter.Locale).
+		Where("ps.product_id IN (?) AND ps.numeric_value IS NOT NULL", matching).
+		Group("pst.name, COALESCE(ps.dimension, '')").
+		Scan(&ranges).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	rangeByKey := make(map[specKey]specRangeRow, len(ranges))
+	for _, r := range ranges {
+		rangeByKey[specKey{Name: r.Name, Dimension: r.Dimension}] = r
+	}
+
+	facets := make([]SpecFacet, 0)
+	for _, row := range rows {
+		if len(facets) == 0 || facets[len(facets)-1].Name != row.Name || facets[len(facets)-1].Dimension != row.Dimension {
+			facet := SpecFacet{Name: row.Name, Dimension: row.Dimension}
+
+			// bounds are in the base unit here; the service converts them for display
+			if r, ok := rangeByKey[specKey{Name: row.Name, Dimension: row.Dimension}]; ok {
+				facet.Min, facet.Max = &r.MinValue, &r.MaxValue
+			}
+
+			facets = append(facets, facet)
+		}
+
+		last := &facets[len(facets)-1]
+		last.Values = append(last.Values, FacetValue{Value: row.Value, Count: row.ProductCount})
+	}
+
+	return facets, nil
+}
+
+// sqlSortOrder whitelists the sort direction before it is concatenated into ORDER BY.
+func sqlSortOrder(order string) string {
+	if strings.EqualFold(order, "desc") {
+		return "DESC"
+	}
+
+	return "ASC"
+}
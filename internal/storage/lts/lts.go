@@ -1,10 +1,9 @@
 package lts
 
 import (
-	"fmt"
 	"international_site/internal/config"
 	"international_site/internal/storage/models"
-	"strings"
+	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -13,24 +12,71 @@ import (
 // Protocol defines the methods for long-term storage.
 type Protocol interface {
 	GetPageBySlug(slug, locale string) (*models.Page, error)
+	GetPageByID(id uint, locale string) (*models.Page, error)
 	GetCategories(locale string) ([]models.ProductCategory, error)
 	GetCategoryBySlug(slug, locale string) (*models.ProductCategory, error)
 	GetCategoryByID(id uint, locale string) (*models.ProductCategory, error)
+	GetCategoryTree(locale string) ([]CategoryRow, error)
+	GetCategoryAncestors(categoryID uint, locale string) ([]CategoryRow, error)
 	GetProductCountByCategory(categoryID uint) (int, error)
 	GetProducts(locale string, offset, limit int) ([]models.Product, int, error)
-	GetProductsByCategory(locale string, categoryID uint, offset, limit int) ([]models.Product, int, error)
+	GetProductsByCategory(locale string, categoryID uint, includeDescendants bool, offset, limit int) ([]models.Product, int, error)
 	GetProductByID(id uint, locale string) (*models.Product, error)
-	GetRelatedProducts(locale string, categoryID, excludeID uint, limit int) ([]models.Product, error)
+	GetProductsByIDs(ids []uint, locale string) ([]models.Product, error)
+	GetRelatedProducts(locale string, productID uint, limit int) ([]RelatedProduct, error)
 	SearchProducts(locale, query string, offset, limit int) ([]models.Product, int, error)
-	FilterProducts(locale string, categoryID uint, search, sortBy, sortOrder string, offset, limit int) ([]models.Product, int, error)
-	GetNews(locale string, offset, limit int) ([]models.News, int, error)
+	FilterProducts(filter ProductFilter) ([]models.Product, int, error)
+	GetSpecFacets(filter ProductFilter) ([]SpecFacet, error)
+	GetSpecDimension(locale, name string) (string, error)
+	GetNews(locale string, now time.Time, offset, limit int) ([]models.News, int, error)
+	GetNewsAfter(locale string, now time.Time, after *ListCursor, limit int) ([]models.News, error)
 	GetNewsByID(id uint, locale string) (*models.News, error)
-	SearchNews(locale, query string, offset, limit int) ([]models.News, int, error)
+	SearchNews(locale, query string, now time.Time, offset, limit int) ([]models.News, int, error)
 	GetDocumentsByType(docType, locale string) ([]models.Document, error)
 	SearchDocuments(locale, query string) ([]models.Document, error)
 	GetContactsByType(contactType, locale string) ([]models.Contact, error)
 	SearchPages(locale, query string) ([]models.Page, error)
+	Search(locale, query string, now time.Time, itemType string, offset, limit int) (*SearchHits, error)
+	SearchAfter(locale, query string, now time.Time, itemType string, after *SearchCursor, limit int) (*SearchHits, error)
+	GetNewsByIDs(ids []uint, locale string) ([]models.News, error)
+	GetPagesByIDs(ids []uint, locale string) ([]models.Page, error)
+	GetDocumentsByIDs(ids []uint, locale string) ([]models.Document, error)
+	Suggest(locale, text string, limit int) ([]SuggestHit, error)
+	GetProductBySKU(sku string) (*models.Product, error)
+	GetClosestWords(locale string, words []string) (map[string]string, error)
+	RefreshSearchVocabulary() error
 	SaveFeedback(feedback models.Feedback) (uint, error)
+	GetLanguages() ([]models.Language, error)
+	CreateProduct(product *models.Product) error
+	UpdateProduct(product *models.Product) error
+	DeleteProduct(id uint) error
+	SetStatus(item any, status string) error
+	UpdatePage(page *models.Page) error
+	UpdateNews(news *models.News) error
+	GetProductSnapshot(id uint) (*models.Product, error)
+	GetVariantIDs(productID uint) ([]uint, error)
+	GetPageSnapshot(id uint) (*models.Page, error)
+	GetNewsSnapshot(id uint) (*models.News, error)
+	CreateRevision(revision *models.Revision) error
+	GetLastRevision(entityType string, entityID uint) (*models.Revision, error)
+	GetRevisions(entityType string, entityID uint) ([]models.Revision, error)
+	GetRevision(id uint) (*models.Revision, error)
+	SetNewsSchedule(id uint, publishAt, unpublishAt *time.Time) error
+	GetNewsGoingLive(from, to time.Time) ([]models.News, error)
+	GetNewsExpiring(from, to time.Time) ([]models.News, error)
+	SetProductOptions(productID uint, options []models.ProductOption) error
+	GetCatalog() ([]models.Product, error)
+	ImportProducts(products []models.Product) error
+	GetPriceLists() ([]models.PriceList, error)
+	GetWarehouses() ([]models.Warehouse, error)
+	GetProductStock(productID uint) ([]models.ProductStock, error)
+	GetProductIDsBySKU(skus []string) (map[string]uint, error)
+	UpsertStock(stock []models.ProductStock) error
+	CategorySlugExists(slug, locale string, excludeID uint) (bool, error)
+	CreateCategory(category *models.ProductCategory) error
+	UpdateCategory(category *models.ProductCategory) error
+	DeleteCategory(id uint) error
+	Transaction(fn func(store Protocol) error) error
 }
 
 // Instance implements the LongTermStorageProtocol for Postgres.
@@ -41,7 +87,8 @@ type Instance struct {
 // New creates a new PostgresStorage instance.
 func New(cfg config.LTSInstance) (*Instance, error) {
 	db, err := gorm.Open(postgres.Open(cfg.GetDSN()), &gorm.Config{
-		PrepareStmt: true,
+		PrepareStmt:    true,
+		TranslateError: true,
 	})
 
 	if err != nil {
@@ -51,6 +98,15 @@ func New(cfg config.LTSInstance) (*Instance, error) {
 	return &Instance{db: db}, nil
 }
 
+// Transaction runs fn against a storage bound to one database transaction,
+// committed when fn returns nil and rolled back otherwise. Methods that open
+// their own transaction run in a savepoint inside it.
+func (i *Instance) Transaction(fn func(store Protocol) error) error {
+	return i.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&Instance{db: tx})
+	})
+}
+
 func (i *Instance) GetPageBySlug(slug, locale string) (*models.Page, error) {
 	var page models.Page
 
@@ -61,12 +117,24 @@ func (i *Instance) GetPageBySlug(slug, locale string) (*models.Page, error) {
 		First(&page).Error
 
 	if err != nil {
-		return nil, err
+		return nil, translateError(err)
 	}
 
 	return &page, nil
 }
 
+func (i *Instance) GetPageByID(id uint, locale string) (*models.Page, error) {
+	var page models.Page
+
+	err := i.db.
+		Debug().
+		Preload("Translations", "language_code = ?", locale).
+		Where("page_id = ?", id).
+		First(&page).Error
+
+	return &page, translateError(err)
+}
+
 func (i *Instance) GetCategories(locale string) ([]models.ProductCategory, error) {
 	var categories []models.ProductCategory
 
@@ -82,29 +150,21 @@ func (i *Instance) GetCategories(locale string) ([]models.ProductCategory, error
 }
 
 func (i *Instance) GetCategoryBySlug(slug, locale string) (*models.ProductCategory, error) {
-	// Ищем по slug из перевода (предполагаем, что slug хранится в поле Description)
-	var categories []models.ProductCategory
+	var category models.ProductCategory
 
 	err := i.db.
 		Debug().
 		Preload("Translations", "language_code = ?", locale).
 		Preload("Children.Translations", "language_code = ?", locale).
-		Find(&categories).Error
+		Joins("JOIN product_category_translations slugs ON slugs.category_id = product_categories.category_id").
+		Where("slugs.language_code = ? AND slugs.slug = ?", locale, slug).
+		First(&category).Error
 
 	if err != nil {
 		return nil, err
 	}
 
-	for _, cat := range categories {
-		for _, trans := range cat.Translations {
-			nameSlug := strings.ToLower(strings.ReplaceAll(trans.Name, " ", "-"))
-			if nameSlug == slug {
-				return &cat, nil
-			}
-		}
-	}
-
-	return nil, fmt.Errorf("category not found")
+	return &category, nil
 }
 
 func (i *Instance) GetCategoryByID(id uint, locale string) (*models.ProductCategory, error) {
@@ -114,10 +174,10 @@ func (i *Instance) GetCategoryByID(id uint, locale string) (*models.ProductCateg
 		Debug().
 		Preload("Translations", "language_code = ?", locale).
 		Preload("Children.Translations", "language_code = ?", locale).
-		Where("id = ?", id).
+		Where("category_id = ?", id).
 		First(&category).Error
 
-	return &category, err
+	return &category, translateError(err)
 }
 
 func (i *Instance) GetProductCountByCategory(categoryID uint) (int, error) {
@@ -125,6 +185,7 @@ func (i *Instance) GetProductCountByCategory(categoryID uint) (int, error) {
 
 	err := i.db.Model(&models.Product{}).
 		Debug().
+		Scopes(parentProducts, published("products")).
 		Where("category_id = ?", categoryID).
 		Count(&count).Error
 
@@ -135,28 +196,33 @@ func (i *Instance) GetProducts(locale string, offset, limit int) ([]models.Produ
 	var products []models.Product
 	var total int64
 
-	i.db.Model(&models.Product{}).Count(&total)
+	i.db.Model(&models.Product{}).Scopes(parentProducts, published("products")).Count(&total)
 
 	err := i.db.
 		Debug().
 		Preload("Translations", "language_code = ?", locale).
 		Preload("Specs.Translations", "language_code = ?", locale).
 		Preload("Category.Translations", "language_code = ?", locale).
+		Preload("Prices", pricesForLocale(locale)).
+		Preload("Stock").
+		Preload("Media", primaryImage(locale)).
+		Preload("Media.Translations", "language_code = ?", locale).
+		Scopes(parentProducts, published("products")).
 		Offset(offset).
 		Limit(limit).
-		Order("sort_order ASC, created_at DESC").
+		Order(productOrder).
 		Find(&products).Error
 
 	return products, int(total), err
 }
 
-func (i *Instance) GetProductsByCategory(locale string, categoryID uint, offset, limit int) ([]models.Product, int, error) {
+func (i *Instance) GetProductsByCategory(locale string, categoryID uint, includeDescendants bool, offset, limit int) ([]models.Product, int, error) {
 	var products []models.Product
 	var total int64
 
 	i.db.Model(&models.Product{}).
 		Debug().
-		Where("category_id = ?", categoryID).
+		Scopes(parentProducts, published("products"), i.inCategory(categoryID, includeDescendants)).
 		Count(&total)
 
 	err := i.db.
@@ -164,10 +230,14 @@ func (i *Instance) GetProductsByCategory(locale string, categoryID uint, offset,
 		Preload("Translations", "language_code = ?", locale).
 		Preload("Specs.Translations", "language_code = ?", locale).
 		Preload("Category.Translations", "language_code = ?", locale).
-		Where("category_id = ?", categoryID).
+		Preload("Prices", pricesForLocale(locale)).
+		Preload("Stock").
+		Preload("Media", primaryImage(locale)).
+		Preload("Media.Translations", "language_code = ?", locale).
+		Scopes(parentProducts, published("products"), i.inCategory(categoryID, includeDescendants)).
 		Offset(offset).
 		Limit(limit).
-		Order("sort_order ASC, created_at DESC").
+		Order(productOrder).
 		Find(&products).Error
 
 	return products, int(total), err
@@ -181,119 +251,114 @@ func (i *Instance) GetProductByID(id uint, locale string) (*models.Product, erro
 		Preload("Translations", "language_code = ?", locale).
 		Preload("Specs.Translations", "language_code = ?", locale).
 		Preload("Category.Translations", "language_code = ?", locale).
-		Where("id = ?", id).
+		Preload("Prices", pricesForLocale(locale)).
+		Preload("Stock").
+		Preload("Media", mediaForLocale(locale)).
+		Preload("Media.Translations", "language_code = ?", locale).
+		Preload("Options", byOrder).
+		Preload("Options.Translations", "language_code = ?", locale).
+		Preload("Options.Values", byOrder).
+		Preload("Options.Values.Translations", "language_code = ?", locale).
+		Preload("Variants", byOrder).
+		Preload("Variants.Translations", "language_code = ?", locale).
+		Preload("Variants.Specs.Translations", "language_code = ?", locale).
+		Preload("Variants.Prices", pricesForLocale(locale)).
+		Preload("Variants.Stock").
+		Preload("Variants.Media", mediaForLocale(locale)).
+		Preload("Variants.Media.Translations", "language_code = ?", locale).
+		Preload("Variants.VariantValues").
+		Preload("VariantValues").
+		Where("product_id = ?", id).
 		First(&product).Error
 
-	return &product, err
+	return &product, translateError(err)
 }
 
-func (i *Instance) GetRelatedProducts(locale string, categoryID, excludeID uint, limit int) ([]models.Product, error) {
+// GetProductsByIDs loads the products with their specs in spec order; the result is not in ids order.
+func (i *Instance) GetProductsByIDs(ids []uint, locale string) ([]models.Product, error) {
 	var products []models.Product
 
 	err := i.db.
 		Debug().
 		Preload("Translations", "language_code = ?", locale).
-		Preload("Category.Translations", "language_code = ?", locale).
-		Where("category_id = ? AND id != ?", categoryID, excludeID).
-		Limit(limit).
-		Order("RANDOM()").
-		Find(&products).Error
-
-	return products, err
-}
-
-func (i *Instance) SearchProducts(locale, query string, offset, limit int) ([]models.Product, int, error) {
-	var products []models.Product
-	var total int64
-
-	query = "%" + strings.ToLower(query) + "%"
-
-	subQuery := i.db.Model(&models.ProductTranslation{}).
-		Debug().
-		Select("product_id").
-		Where("(LOWER(name) LIKE ? OR LOWER(description) LIKE ? OR LOWER(short_description) LIKE ?) AND language_code = ?",
-			query, query, query, locale)
-
-	i.db.Model(&models.Product{}).
-		Debug().
-		Where("id IN (?)", subQuery).
-		Count(&total)
-
-	err := i.db.
-		Debug().
-		Preload("Translations", "language_code = ?", locale).
+		Preload("Specs", byOrder).
 		Preload("Specs.Translations", "language_code = ?", locale).
 		Preload("Category.Translations", "language_code = ?", locale).
-		Where("id IN (?)", subQuery).
-		Offset(offset).
-		Limit(limit).
-		Order("sort_order ASC, created_at DESC").
+		Preload("Prices", pricesForLocale(locale)).
+		Preload("Stock").
+		Preload("Media", primaryImage(locale)).
+		Scopes(published("products")).
+		Where("product_id IN ?", ids).
 		Find(&products).Error
 
-	return products, int(total), err
+	return products, err
 }
 
-func (i *Instance) FilterProducts(locale string, categoryID uint, search, sortBy, sortOrder string, offset, limit int) ([]models.Product, int, error) {
+func (i *Instance) FilterProducts(filter ProductFilter) ([]models.Product, int, error) {
 	var products []models.Product
 	var total int64
 
-	query := i.db.Model(&models.Product{})
-
-	if categoryID > 0 {
-		query = query.Where("category_id = ?", categoryID)
-	}
+	query := i.filterProducts(i.db.Model(&models.Product{}), filter)
+
+	if filter.After != nil {
+		query = query.Scopes(productsAfter(filter.After))
+	} else {
+		i.filterProducts(i.db.Model(&models.Product{}).Debug(), filter).
+			Count(&total)
+
+		sortOrder := sqlSortOrder(filter.SortOrder)
+
+		switch filter.SortBy {
+		case "name":
+			query = query.
+				Debug().
+				Joins("LEFT JOIN product_translations ON products.product_id = product_translations.product_id AND product_translations.language_code = ?", filter.Locale).
+				Order("product_translations.name " + sortOrder)
+		case "created_at":
+			query = query.Order("products.created_at " + sortOrder)
+		case "price":
+			query = sortByPrice(query.Debug(), filter.Locale, sortOrder)
+		default:
+			query = query.Order("products.sort_order " + sortOrder + ", products.created_at DESC")
+		}
 
-	if search != "" {
-		searchQuery := "%" + strings.ToLower(search) + "%"
-		subQuery := i.db.Model(&models.ProductTranslation{}).
-			Debug().
-			Select("product_id").
-			Where("(LOWER(name) LIKE ? OR LOWER(description) LIKE ?) AND language_code = ?",
-				searchQuery, searchQuery, locale)
-		query = query.Where("id IN (?)", subQuery)
-	}
+		// the id breaks ties, as in cursor pages, so that pages do not overlap
+		query = query.Order("products.product_id DESC")
 
-	query.Count(&total)
-
-	switch sortBy {
-	case "name":
-		query = query.
-			Debug().
-			Joins("LEFT JOIN product_translations ON products.id = product_translations.product_id AND product_translations.language_code = ?", locale).
-			Order("product_translations.name " + sortOrder)
-	case "created_at":
-		query = query.Order("created_at " + sortOrder)
-	default:
-		query = query.Order("sort_order " + sortOrder)
+		query = query.Offset(filter.Offset)
 	}
 
 	err := query.
 		Debug().
-		Preload("Translations", "language_code = ?", locale).
-		Preload("Specs.Translations", "language_code = ?", locale).
-		Preload("Category.Translations", "language_code = ?", locale).
-		Offset(offset).
-		Limit(limit).
+		Preload("Translations", "language_code = ?", filter.Locale).
+		Preload("Specs.Translations", "language_code = ?", filter.Locale).
+		Preload("Category.Translations", "language_code = ?", filter.Locale).
+		Preload("Prices", pricesForLocale(filter.Locale)).
+		Preload("Stock").
+		Preload("Media", primaryImage(filter.Locale)).
+		Preload("Media.Translations", "language_code = ?", filter.Locale).
+		Limit(filter.Limit).
 		Find(&products).Error
 
 	return products, int(total), err
 }
 
-func (i *Instance) GetNews(locale string, offset, limit int) ([]models.News, int, error) {
+// GetNews returns the news that are live at now, the newest first.
+func (i *Instance) GetNews(locale string, now time.Time, offset, limit int) ([]models.News, int, error) {
 	var news []models.News
 	var total int64
 
 	i.db.Model(&models.News{}).
-		Where("published = ?", true).
+		Scopes(liveNews(now)).
 		Count(&total)
 
 	err := i.db.
 		Debug().
 		Preload("Translations", "language_code = ?", locale).
-		Where("published = ?", true).
+		Scopes(liveNews(now)).
 		Offset(offset).
 		Limit(limit).
-		Order("created_at DESC").
+		Order(newsDateOrder).
 		Find(&news).Error
 
 	return news, int(total), err
@@ -305,39 +370,10 @@ func (i *Instance) GetNewsByID(id uint, locale string) (*models.News, error) {
 	err := i.db.
 		Debug().
 		Preload("Translations", "language_code = ?", locale).
-		Where("id = ? AND published = ?", id, true).
+		Where("news_id = ?", id).
 		First(&news).Error
 
-	return &news, err
-}
-
-func (i *Instance) SearchNews(locale, query string, offset, limit int) ([]models.News, int, error) {
-	var news []models.News
-	var total int64
-
-	query = "%" + strings.ToLower(query) + "%"
-
-	subQuery := i.db.Model(&models.NewsTranslation{}).
-		Debug().
-		Select("news_id").
-		Where("(LOWER(title) LIKE ? OR LOWER(content) LIKE ? OR LOWER(excerpt) LIKE ?) AND language_code = ?",
-			query, query, query, locale)
-
-	i.db.Model(&models.News{}).
-		Debug().
-		Where("id IN (?) AND published = ?", subQuery, true).
-		Count(&total)
-
-	err := i.db.
-		Debug().
-		Preload("Translations", "language_code = ?", locale).
-		Where("id IN (?) AND published = ?", subQuery, true).
-		Offset(offset).
-		Limit(limit).
-		Order("created_at DESC").
-		Find(&news).Error
-
-	return news, int(total), err
+	return &news, translateError(err)
 }
 
 func (i *Instance) GetDocumentsByType(docType, locale string) ([]models.Document, error) {
@@ -346,6 +382,7 @@ func (i *Instance) GetDocumentsByType(docType, locale string) ([]models.Document
 	err := i.db.
 		Debug().
 		Preload("Translations", "language_code = ?", locale).
+		Scopes(published("documents")).
 		Where("type = ?", docType).
 		Order("created_at DESC").
 		Find(&documents).Error
@@ -353,22 +390,6 @@ func (i *Instance) GetDocumentsByType(docType, locale string) ([]models.Document
 	return documents, err
 }
 
-func (i *Instance) SearchDocuments(locale, query string) ([]models.Document, error) {
-	var documents []models.Document
-
-	query = "%" + strings.ToLower(query) + "%"
-
-	err := i.db.
-		Debug().
-		Preload("Translations", "language_code = ?", locale).
-		Joins("LEFT JOIN document_translations ON documents.id = document_translations.document_id AND document_translations.language_code = ?", locale).
-		Where("LOWER(document_translations.title) LIKE ? OR LOWER(document_translations.description) LIKE ?", query, query).
-		Order("created_at DESC").
-		Find(&documents).Error
-
-	return documents, err
-}
-
 func (i *Instance) GetContactsByType(contactType, locale string) ([]models.Contact, error) {
 	var contacts []models.Contact
 
@@ -382,22 +403,6 @@ func (i *Instance) GetContactsByType(contactType, locale string) ([]models.Conta
 	return contacts, err
 }
 
-func (i *Instance) SearchPages(locale, query string) ([]models.Page, error) {
-	var pages []models.Page
-
-	query = "%" + strings.ToLower(query) + "%"
-
-	err := i.db.
-		Debug().
-		Preload("Translations", "language_code = ?", locale).
-		Joins("LEFT JOIN page_translations ON pages.id = page_translations.page_id AND page_translations.language_code = ?", locale).
-		Where("LOWER(page_translations.title) LIKE ? OR LOWER(page_translations.content) LIKE ?", query, query).
-		Order("created_at DESC").
-		Find(&pages).Error
-
-	return pages, err
-}
-
 func (i *Instance) SaveFeedback(feedback models.Feedback) (uint, error) {
 	err := i.db.Create(&feedback).Error
 	return feedback.ID, err
@@ -0,0 +1,52 @@
+package lts
+
+import (
+	"regexp"
+	"strconv"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// dryRunInstance builds SQL without a database: queries are rendered into
+// their Statement and never sent.
+func dryRunInstance(t *testing.T) *Instance {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.Open("host=localhost user=catalog dbname=catalog sslmode=disable"), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("open dry-run database: %v", err)
+	}
+
+	return &Instance{db: db}
+}
+
+var bindParameter = regexp.MustCompile(`\$(\d+)`)
+
+// checkBindParameters fails unless the statement uses exactly the parameters
+// $1..$n for its n arguments.
+func checkBindParameters(t *testing.T, stmt *gorm.Statement) {
+	t.Helper()
+
+	used := make(map[int]bool)
+	for _, match := range bindParameter.FindAllStringSubmatch(stmt.SQL.String(), -1) {
+		n, _ := strconv.Atoi(match[1])
+		used[n] = true
+	}
+
+	if len(used) != len(stmt.Vars) {
+		t.Errorf("SQL uses %d parameters for %d arguments: %s", len(used), len(stmt.Vars), stmt.SQL.String())
+	}
+
+	for n := 1; n <= len(stmt.Vars); n++ {
+		if !used[n] {
+			t.Errorf("argument $%d (%v) is not used in: %s", n, stmt.Vars[n-1], stmt.SQL.String())
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package lts
+
+import (
+	"international_site/internal/storage/models"
+
+	"gorm.io/gorm"
+)
+
+// mediaForLocale limits a Media preload to items shared by all languages plus
+// those made for locale; within a position the locale-specific item goes first.
+func mediaForLocale(locale string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Where("language_code IS NULL OR language_code = ?", locale).
+			Order("sort_order ASC, language_code NULLS LAST, media_id ASC")
+	}
+}
+
+// primaryImage limits a Media preload to the primary image shown in product lists.
+func primaryImage(locale string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return mediaForLocale(locale)(db).Where("kind = ? AND is_primary", models.MediaImage)
+	}
+}
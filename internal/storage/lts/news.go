@@ -0,0 +1,70 @@
+package lts
+
+import (
+	"international_site/internal/storage/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// newsDate is the date a news item is listed under: its scheduled
+// publication time, or its creation time when it went live at once. The id
+// breaks ties in newsDateOrder, as in cursor pages.
+const (
+	newsDate      = "COALESCE(news.publish_at, news.created_at)"
+	newsDateOrder = newsDate + " DESC, news.news_id DESC"
+)
+
+// liveNews keeps the published news whose publication window contains now.
+// The window is TIMESTAMPTZ, compared in UTC.
+func liveNews(now time.Time) func(*gorm.DB) *gorm.DB {
+	now = now.UTC()
+
+	return func(db *gorm.DB) *gorm.DB {
+		return published("news")(db).
+			Where("news.publish_at IS NULL OR news.publish_at <= ?", now).
+			Where("news.unpublish_at IS NULL OR news.unpublish_at > ?", now)
+	}
+}
+
+// GetNewsGoingLive returns the published news scheduled to appear in (from, to].
+func (i *Instance) GetNewsGoingLive(from, to time.Time) ([]models.News, error) {
+	return i.scheduledNews("publish_at", from, to)
+}
+
+// GetNewsExpiring returns the published news scheduled to disappear in (from, to].
+func (i *Instance) GetNewsExpiring(from, to time.Time) ([]models.News, error) {
+	return i.scheduledNews("unpublish_at", from, to)
+}
+
+func (i *Instance) scheduledNews(column string, from, to time.Time) ([]models.News, error) {
+	var news []models.News
+
+	err := i.db.
+		Debug().
+		Scopes(published("news")).
+		Where("news."+column+" > ? AND news."+column+" <= ?", from.UTC(), to.UTC()).
+		Order("news." + column + " ASC").
+		Find(&news).Error
+
+	return news, err
+}
+
+// SetNewsSchedule sets or clears the publication window of a news item.
+func (i *Instance) SetNewsSchedule(id uint, publishAt, unpublishAt *time.Time) error {
+	res := i.db.
+		Debug().
+		Model(&models.News{ID: id}).
+		Select("publish_at", "unpublish_at", "updated_at").
+		Updates(&models.News{PublishAt: publishAt, UnpublishAt: unpublishAt})
+
+	if res.Error != nil {
+		return translateError(res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package lts
+
+import (
+	"international_site/internal/storage/models"
+
+	"gorm.io/gorm"
+)
+
+// pricesForLocale limits a Prices preload to the price list shown for locale,
+// cheapest tier first, with the list currency filled in.
+func pricesForLocale(locale string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Select("product_prices.*, price_lists.currency").
+			Joins("JOIN price_list_locales ON price_list_locales.price_list_id = product_prices.price_list_id").
+			Joins("JOIN price_lists ON price_lists.price_list_id = product_prices.price_list_id").
+			Where("price_list_locales.language_code = ?", locale).
+			Order("product_prices.min_quantity ASC")
+	}
+}
+
+// sortByPrice orders products by their lowest tier in the locale's price list;
+// products priced on request and products without a price go last.
+func sortByPrice(db *gorm.DB, locale, sortOrder string) *gorm.DB {
+	return db.
+		Joins(`LEFT JOIN LATERAL (
+			SELECT pp.amount
+			FROM product_prices pp
+			JOIN price_list_locales pll ON pll.price_list_id = pp.price_list_id AND pll.language_code = ?
+			WHERE pp.product_id = products.product_id
+			ORDER BY pp.min_quantity ASC
+			LIMIT 1
+		) base_price ON true`, locale).
+		Order("products.price_on_request ASC").
+		Order("base_price.amount " + sortOrder + " NULLS LAST")
+}
+
+func (i *Instance) GetPriceLists() ([]models.PriceList, error) {
+	var priceLists []models.PriceList
+
+	err := i.db.
+		Debug().
+		Preload("Locales").
+		Order("price_list_id ASC").
+		Find(&priceLists).Error
+
+	return priceLists, err
+}
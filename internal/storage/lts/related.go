@@ -0,0 +1,150 @@
+package lts
+
+import (
+	"database/sql"
+	"international_site/internal/storage/models"
+)
+
+// Weights of the related products score. A curated link outweighs any
+// combination of category and spec similarity.
+const (
+	relatedCategoryWeight  = 3.0
+	relatedSpecNameWeight  = 0.5
+	relatedSpecValueWeight = 1.0
+	relatedLinkWeight      = 100.0
+)
+
+// relatedProductsQuery scores every listed product against @id:
+//   - category: 1 / (1 + distance to the closest common ancestor category of both products),
+//     so the same category scores 1 and a sibling category 1/3;
+//   - specs: the number of spec names, and of name/value pairs, shared with @id in @locale;
+//   - curated links from @id.
+//
+// Products sharing nothing with @id are left out.
+const relatedProductsQuery = `WITH RECURSIVE ` + categoryClosureCTE + `,
+source AS (
+	SELECT category_id FROM products WHERE product_id = @id
+),
+category_scores AS (
+	SELECT p.product_id, MAX(1.0 / (1 + sa.depth + ca.depth)) AS score
+	FROM products p
+	JOIN category_closure ca ON ca.descendant_id = p.category_id
+	JOIN category_closure sa ON sa.ancestor_id = ca.ancestor_id
+	JOIN source s ON sa.descendant_id = s.category_id
+	GROUP BY p.product_id
+),
+source_specs AS (
+	SELECT pst.name, pst.value
+	FROM product_specs ps
+	JOIN product_spec_translations pst ON pst.spec_id = ps.spec_id AND pst.language_code = @locale
+	WHERE ps.product_id = @id
+),
+spec_scores AS (
+	SELECT ps.product_id,
+		COUNT(DISTINCT pst.name) AS shared_names,
+		COUNT(DISTINCT pst.name) FILTER (WHERE pst.value = ss.value) AS shared_values
+	FROM product_specs ps
+	JOIN product_spec_translations pst ON pst.spec_id = ps.spec_id AND pst.language_code = @locale
+	JOIN source_specs ss ON ss.name = pst.name
+	GROUP BY ps.product_id
+),
+links AS (
+	SELECT linked_product_id AS product_id, link_type, sort_order
+	FROM product_links
+	WHERE product_id = @id
+)
+SELECT
+	p.product_id,
+	COALESCE(cs.score, 0) * CAST(@category_weight AS double precision)
+		+ COALESCE(sp.shared_names, 0) * CAST(@spec_name_weight AS double precision)
+		+ COALESCE(sp.shared_values, 0) * CAST(@spec_value_weight AS double precision)
+		+ CASE WHEN l.product_id IS NULL THEN 0 ELSE CAST(@link_weight AS double precision) END AS score,
+	COALESCE(l.link_type, '') AS link_type
+FROM products p
+LEFT JOIN category_scores cs ON cs.product_id = p.product_id
+LEFT JOIN spec_scores sp ON sp.product_id = p.product_id
+LEFT JOIN links l ON l.product_id = p.product_id
+WHERE p.product_id <> @id
+	AND p.status = @published
+	AND (p.parent_id IS NULL OR l.product_id IS NOT NULL)
+	AND (cs.product_id IS NOT NULL OR sp.product_id IS NOT NULL OR l.product_id IS NOT NULL)
+ORDER BY score DESC, l.sort_order ASC NULLS LAST, p.sort_order ASC, p.product_id ASC
+LIMIT @limit`
+
+// RelatedProduct is a product of GetRelatedProducts with its score; Relation
+// is the link type of a curated link.
+type RelatedProduct struct {
+	Product  models.Product
+	Score    float64
+	Relation string
+}
+
+type relatedRow struct {
+	ProductID uint
+	Score     float64
+	LinkType  string
+}
+
+// GetRelatedProducts returns up to limit products ranked by relatedProductsQuery.
+// Equal scores are ordered by sort_order and id, so the result is stable. An
+// unpublished source product is ErrNotFound.
+func (i *Instance) GetRelatedProducts(locale string, productID uint, limit int) ([]RelatedProduct, error) {
+	var source models.Product
+
+	err := i.db.
+		Debug().
+		Select("product_id").
+		Scopes(published("products")).
+		Where("product_id = ?", productID).
+		First(&source).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	var rows []relatedRow
+
+	err = i.db.
+		Debug().
+		Raw(relatedProductsQuery,
+			sql.Named("id", productID),
+			sql.Named("locale", locale),
+			sql.Named("limit", limit),
+			sql.Named("published", models.StatusPublished),
+			sql.Named("category_weight", relatedCategoryWeight),
+			sql.Named("spec_name_weight", relatedSpecNameWeight),
+			sql.Named("spec_value_weight", relatedSpecValueWeight),
+			sql.Named("link_weight", relatedLinkWeight),
+		).
+		Scan(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ProductID)
+	}
+
+	products, err := i.GetProductsByIDs(ids, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]models.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	related := make([]RelatedProduct, 0, len(rows))
+	for _, row := range rows {
+		if product, ok := byID[row.ProductID]; ok {
+			related = append(related, RelatedProduct{
+				Product:  product,
+				Score:    row.Score,
+				Relation: row.LinkType,
+			})
+		}
+	}
+
+	return related, nil
+}
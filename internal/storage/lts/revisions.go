@@ -0,0 +1,192 @@
+package lts
+
+import (
+	"errors"
+	"international_site/internal/storage/models"
+
+	"gorm.io/gorm"
+)
+
+// GetProductSnapshot returns everything UpdateProduct and SetProductOptions
+// write, in every language: the state a product revision stores.
+func (i *Instance) GetProductSnapshot(id uint) (*models.Product, error) {
+	var product models.Product
+
+	err := i.db.
+		Debug().
+		Preload("Translations").
+		Preload("Specs", byOrder).
+		Preload("Specs.Translations").
+		Preload("Prices", func(db *gorm.DB) *gorm.DB { return db.Order("price_list_id ASC, min_quantity ASC") }).
+		Preload("Media", byOrder).
+		Preload("Media.Translations").
+		Preload("Links", byOrder).
+		Preload("VariantValues", func(db *gorm.DB) *gorm.DB { return db.Order("option_id ASC") }).
+		Preload("Options", byOrder).
+		Preload("Options.Translations").
+		Preload("Options.Values", byOrder).
+		Preload("Options.Values.Translations").
+		Where("product_id = ?", id).
+		First(&product).Error
+
+	return &product, translateError(err)
+}
+
+// GetVariantIDs lists the variants of a product, which are deleted together with it.
+func (i *Instance) GetVariantIDs(productID uint) ([]uint, error) {
+	var ids []uint
+
+	err := i.db.Model(&models.Product{}).
+		Debug().
+		Where("parent_id = ?", productID).
+		Order("product_id ASC").
+		Pluck("product_id", &ids).Error
+
+	return ids, err
+}
+
+// GetPageSnapshot returns a page with its translations in every language.
+func (i *Instance) GetPageSnapshot(id uint) (*models.Page, error) {
+	var page models.Page
+
+	err := i.db.
+		Debug().
+		Preload("Translations").
+		Where("page_id = ?", id).
+		First(&page).Error
+
+	return &page, translateError(err)
+}
+
+// GetNewsSnapshot returns a news item with its translations in every language.
+func (i *Instance) GetNewsSnapshot(id uint) (*models.News, error) {
+	var news models.News
+
+	err := i.db.
+		Debug().
+		Preload("Translations").
+		Where("news_id = ?", id).
+		First(&news).Error
+
+	return &news, translateError(err)
+}
+
+// UpdatePage overwrites the page row and replaces its translations. The
+// publication status is left as it is.
+func (i *Instance) UpdatePage(page *models.Page) error {
+	err := i.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Debug().
+			Model(page).
+			Select("slug", "template", "updated_at").
+			Updates(page)
+
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		if err := tx.Debug().Where("page_id = ?", page.ID).Delete(&models.PageTranslation{}).Error; err != nil {
+			return err
+		}
+
+		for idx := range page.Translations {
+			page.Translations[idx].PageID = page.ID
+		}
+
+		if len(page.Translations) == 0 {
+			return nil
+		}
+
+		return tx.Debug().Create(&page.Translations).Error
+	})
+
+	return translateError(err)
+}
+
+// UpdateNews overwrites the news row and replaces its translations. The
+// publication status is left as it is.
+func (i *Instance) UpdateNews(news *models.News) error {
+	err := i.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Debug().
+			Model(news).
+			Select("image_url", "publish_at", "unpublish_at", "updated_at").
+			Updates(news)
+
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected == 0 {
+			return ErrNotFound
+		}
+
+		if err := tx.Debug().Where("news_id = ?", news.ID).Delete(&models.NewsTranslation{}).Error; err != nil {
+			return err
+		}
+
+		for idx := range news.Translations {
+			news.Translations[idx].NewsID = news.ID
+		}
+
+		if len(news.Translations) == 0 {
+			return nil
+		}
+
+		return tx.Debug().Create(&news.Translations).Error
+	})
+
+	return translateError(err)
+}
+
+func (i *Instance) CreateRevision(revision *models.Revision) error {
+	return i.db.Debug().Create(revision).Error
+}
+
+// GetLastRevision returns the newest revision of an entity, or nil when it has none.
+func (i *Instance) GetLastRevision(entityType string, entityID uint) (*models.Revision, error) {
+	var revision models.Revision
+
+	err := i.db.
+		Debug().
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("revision_id DESC").
+		First(&revision).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &revision, nil
+}
+
+// GetRevisions lists the revisions of an entity, newest first, without snapshots.
+func (i *Instance) GetRevisions(entityType string, entityID uint) ([]models.Revision, error) {
+	var revisions []models.Revision
+
+	err := i.db.
+		Debug().
+		Omit("snapshot").
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Order("revision_id DESC").
+		Find(&revisions).Error
+
+	return revisions, err
+}
+
+func (i *Instance) GetRevision(id uint) (*models.Revision, error) {
+	var revision models.Revision
+
+	err := i.db.
+		Debug().
+		Where("revision_id = ?", id).
+		First(&revision).Error
+
+	return &revision, translateError(err)
+}
@@ -0,0 +1,521 @@
+package lts
+
+import (
+	"database/sql"
+	"html"
+	"international_site/internal/storage/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SearchHits is one page of Search with the total of the selected type and
+// the count of every type. Fuzzy is set when the hits matched by similarity.
+type SearchHits struct {
+	Hits   []SearchHit
+	Total  int
+	Facets []SearchFacet
+	Fuzzy  bool
+}
+
+// SearchHit is a matching item before its data is loaded; Type is a service
+// item type.
+type SearchHit struct {
+	Type    string
+	ID      uint
+	Rank    float64
+	Snippet string
+}
+
+// SearchFacet counts the matches of one type; Rank is the best of them.
+type SearchFacet struct {
+	Type  string
+	Count int
+	Rank  float64
+}
+
+// searchQuery parses the visitor's query with the websearch syntax ("quoted
+// phrase", or, -word) in the text search configuration of the locale, the
+// same lang_regconfig the search_vector columns are built with.
+const searchQuery = "websearch_to_tsquery(lang_regconfig(@locale), @query)"
+
+// searchTable describes a translation table with a generated search_vector
+// column: the id of the translated row, its title and the text snippets are
+// cut from.
+type searchTable struct {
+	table string
+	id    string
+	title string
+	body  string
+}
+
+// pageText joins the string values of a page's JSON content, so that
+// snippets show the text of the page rather than its markup keys.
+const pageText = `(SELECT string_agg(v #>> '{}', ' ')
+	FROM jsonb_path_query(COALESCE(NULLIF(content, ''), '{}')::jsonb, 'strict $.**') v
+	WHERE jsonb_typeof(v) = 'string')`
+
+var (
+	productSearch  = searchTable{"product_translations", "product_id", "name", "CONCAT_WS(' ', short_description, description)"}
+	newsSearch     = searchTable{"news_translations", "news_id", "title", "CONCAT_WS(' ', excerpt, content)"}
+	pageSearch     = searchTable{"page_translations", "page_id", "title", "CONCAT_WS(' ', meta_description, " + pageText + ")"}
+	documentSearch = searchTable{"document_translations", "document_id", "title", "COALESCE(description, '')"}
+)
+
+// searchMatches selects match_id, rank, title and body for every translation
+// in locale whose search_vector matches the query. Each translation table has
+// a GIN index on search_vector.
+func (i *Instance) searchMatches(from searchTable, locale, query string) *gorm.DB {
+	args := []any{sql.Named("locale", locale), sql.Named("query", strings.TrimSpace(query))}
+
+	return i.db.Table(from.table).
+		Select(from.id+" AS match_id, ts_rank(search_vector, "+searchQuery+") AS rank, "+
+			from.title+" AS title, "+from.body+" AS body", args...).
+		Where("language_code = @locale AND search_vector @@ "+searchQuery, args...)
+}
+
+// fuzzyMatches is the typo-tolerant fallback of searchMatches: it selects the
+// translations whose title has a part similar to the query, ranked by pg_trgm
+// word_similarity. The trigram indexes on LOWER(title) serve the <% operator.
+func (i *Instance) fuzzyMatches(from searchTable, locale, query string) *gorm.DB {
+	args := []any{sql.Named("locale", locale), sql.Named("query", strings.ToLower(strings.TrimSpace(query)))}
+
+	return i.db.Table(from.table).
+		Select(from.id+" AS match_id, word_similarity(@query, LOWER("+from.title+")) AS rank, "+
+			from.title+" AS title, "+from.body+" AS body", args...).
+		Where("language_code = @locale AND @query <% LOWER("+from.title+")", args...)
+}
+
+// matcher is searchMatches or fuzzyMatches.
+type matcher func(from searchTable, locale, query string) *gorm.DB
+
+// minSearchRank is the rank below which full-text matches are too weak to
+// stand alone: a single mention in a body text ranks about 0.012, in a title
+// about 0.06. Weaker matches give way to similar titles when there are any.
+const minSearchRank = 0.02
+
+// matchStats are the number of matches and the best rank among them.
+type matchStats struct {
+	Count int64
+	Rank  float64
+}
+
+// weak reports whether full-text matches should give way to similar titles.
+func (s matchStats) weak() bool {
+	return s.Count == 0 || s.Rank < minSearchRank
+}
+
+// countMatches counts the rows of a searched* query and finds their best rank.
+func countMatches(db *gorm.DB, rank string) (matchStats, error) {
+	var stats matchStats
+
+	err := db.Debug().
+		Select("COUNT(*) AS count, COALESCE(MAX(" + rank + "), 0) AS rank").
+		Scan(&stats).Error
+
+	return stats, err
+}
+
+// bestMatcher returns searchMatches, or fuzzyMatches when the full-text
+// matches are weak and similar titles exist, with the number of matches
+// stats reports for it.
+func (i *Instance) bestMatcher(stats func(match matcher) (matchStats, error)) (matcher, int64, error) {
+	exact, err := stats(i.searchMatches)
+	if err != nil || !exact.weak() {
+		return i.searchMatches, exact.Count, err
+	}
+
+	fuzzy, err := stats(i.fuzzyMatches)
+	if err != nil || fuzzy.Count == 0 {
+		return i.searchMatches, exact.Count, err
+	}
+
+	return i.fuzzyMatches, fuzzy.Count, nil
+}
+
+// searchedProducts matches parent products by their translation, or by their
+// own or a variant's SKU; productRank is their relevance.
+func (i *Instance) searchedProducts(match matcher, locale, query string) *gorm.DB {
+	return i.db.Model(&models.Product{}).
+		Joins("LEFT JOIN (?) AS matches ON matches.match_id = products.product_id",
+			match(productSearch, locale, query)).
+		Joins("LEFT JOIN (?) AS skus ON skus.sku_id = products.product_id", i.skuMatches(query)).
+		Scopes(parentProducts, published("products")).
+		Where("matches.match_id IS NOT NULL OR skus.sku_id IS NOT NULL")
+}
+
+// SearchProducts returns the published products matching the query, the
+// most relevant first. Names similar to the query are matched instead when
+// the full-text matches are missing or weak.
+func (i *Instance) SearchProducts(locale, query string, offset, limit int) ([]models.Product, int, error) {
+	var products []models.Product
+
+	match, total, err := i.bestMatcher(func(match matcher) (matchStats, error) {
+		return countMatches(i.searchedProducts(match, locale, query), productRank)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = i.searchedProducts(match, locale, query).
+		Debug().
+		Select("products.*, "+productRank+" AS rank").
+		Preload("Translations", "language_code = ?", locale).
+		Preload("Specs.Translations", "language_code = ?", locale).
+		Preload("Category.Translations", "language_code = ?", locale).
+		Preload("Prices", pricesForLocale(locale)).
+		Preload("Stock").
+		Preload("Media", primaryImage(locale)).
+		Preload("Media.Translations", "language_code = ?", locale).
+		Order("rank DESC, " + productOrder).
+		Offset(offset).
+		Limit(limit).
+		Find(&products).Error
+
+	return products, int(total), err
+}
+
+func (i *Instance) searchedNews(match matcher, locale, query string, now time.Time) *gorm.DB {
+	return i.db.Model(&models.News{}).
+		Joins("JOIN (?) AS matches ON matches.match_id = news.news_id",
+			match(newsSearch, locale, query)).
+		Scopes(liveNews(now))
+}
+
+// SearchNews returns the news live at now matching the query, the most
+// relevant first, falling back to similar titles like SearchProducts.
+func (i *Instance) SearchNews(locale, query string, now time.Time, offset, limit int) ([]models.News, int, error) {
+	var news []models.News
+
+	match, total, err := i.bestMatcher(func(match matcher) (matchStats, error) {
+		return countMatches(i.searchedNews(match, locale, query, now), "matches.rank")
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = i.searchedNews(match, locale, query, now).
+		Debug().
+		Select("news.*, matches.rank").
+		Preload("Translations", "language_code = ?", locale).
+		Order("rank DESC, " + newsDateOrder).
+		Offset(offset).
+		Limit(limit).
+		Find(&news).Error
+
+	return news, int(total), err
+}
+
+func (i *Instance) searchedDocuments(match matcher, locale, query string) *gorm.DB {
+	return i.db.Model(&models.Document{}).
+		Joins("JOIN (?) AS matches ON matches.match_id = documents.document_id",
+			match(documentSearch, locale, query)).
+		Scopes(published("documents"))
+}
+
+func (i *Instance) SearchDocuments(locale, query string) ([]models.Document, error) {
+	var documents []models.Document
+
+	match, _, err := i.bestMatcher(func(match matcher) (matchStats, error) {
+		return countMatches(i.searchedDocuments(match, locale, query), "matches.rank")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.searchedDocuments(match, locale, query).
+		Debug().
+		Select("documents.*, matches.rank").
+		Preload("Translations", "language_code = ?", locale).
+		Order("rank DESC, documents.created_at DESC").
+		Find(&documents).Error
+
+	return documents, err
+}
+
+func (i *Instance) searchedPages(match matcher, locale, query string) *gorm.DB {
+	return i.db.Model(&models.Page{}).
+		Joins("JOIN (?) AS matches ON matches.match_id = pages.page_id",
+			match(pageSearch, locale, query)).
+		Scopes(published("pages"))
+}
+
+func (i *Instance) SearchPages(locale, query string) ([]models.Page, error) {
+	var pages []models.Page
+
+	match, _, err := i.bestMatcher(func(match matcher) (matchStats, error) {
+		return countMatches(i.searchedPages(match, locale, query), "matches.rank")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = i.searchedPages(match, locale, query).
+		Debug().
+		Select("pages.*, matches.rank").
+		Preload("Translations", "language_code = ?", locale).
+		Order("rank DESC, pages.created_at DESC").
+		Find(&pages).Error
+
+	return pages, err
+}
+
+// searchHits unions the matches of every searchable type into one
+// (type, id, rank, title, body) table; the types are the service item types.
+func (i *Instance) searchHits(match matcher, locale, query string, now time.Time) *gorm.DB {
+	return i.db.Table("((?) UNION ALL (?) UNION ALL (?) UNION ALL (?)) AS hits",
+		i.searchedProducts(match, locale, query).Select("'product' AS type, products.product_id AS id, "+productRank+" AS rank, matches.title, matches.body"),
+		i.searchedNews(match, locale, query, now).Select("'news' AS type, news.news_id AS id, matches.rank, matches.title, matches.body"),
+		i.searchedPages(match, locale, query).Select("'page' AS type, pages.page_id AS id, matches.rank, matches.title, matches.body"),
+		i.searchedDocuments(match, locale, query).Select("'document' AS type, documents.document_id AS id, matches.rank, matches.title, matches.body"),
+	)
+}
+
+// Snippet highlighting: ts_headline wraps matched words in private-use
+// sentinels, which survive HTML escaping and are then turned into <mark>.
+const (
+	snippetStart = "\ue000"
+	snippetStop  = "\ue001"
+
+	snippetOptions = "StartSel=" + snippetStart + ", StopSel=" + snippetStop +
+		`, MinWords=15, MaxWords=35, MaxFragments=2, FragmentDelimiter=" … "`
+	titleSnippetOptions = "StartSel=" + snippetStart + ", StopSel=" + snippetStop + ", HighlightAll=true"
+)
+
+// searchSnippet cuts the snippet from the body when the body matched and
+// highlights the title otherwise. Markup is stripped from the body first.
+// Postgres evaluates it only for the rows of the requested page.
+const searchSnippet = `COALESCE(CASE
+	WHEN to_tsvector(lang_regconfig(@locale), hits.body) @@ ` + searchQuery + `
+	THEN ts_headline(lang_regconfig(@locale), regexp_replace(hits.body, '<[^>]*>', ' ', 'g'), ` + searchQuery + `, @snippet_options)
+	ELSE ts_headline(lang_regconfig(@locale), hits.title, ` + searchQuery + `, @title_options)
+END, '') AS snippet`
+
+var snippetMarker = strings.NewReplacer(snippetStart, "<mark>", snippetStop, "</mark>")
+
+// highlightSnippet escapes a ts_headline result for HTML and marks the matches.
+func highlightSnippet(snippet string) string {
+	return snippetMarker.Replace(html.EscapeString(snippet))
+}
+
+// Search ranks the matching products, news, pages and documents together
+// and returns one page of them, optionally of a single type, with the total
+// and the count of every type. When the full-text matches of the selected
+// type are missing or weak, titles similar to the query are matched instead
+// if there are any, and Fuzzy is set.
+func (i *Instance) Search(locale, query string, now time.Time, itemType string, offset, limit int) (*SearchHits, error) {
+	result := &SearchHits{}
+
+	facets, err := i.searchFacets(i.searchMatches, locale, query, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if selectedStats(facets, itemType).weak() {
+		fuzzy, err := i.searchFacets(i.fuzzyMatches, locale, query, now)
+		if err != nil {
+			return nil, err
+		}
+
+		if selectedStats(fuzzy, itemType).Count > 0 {
+			facets, result.Fuzzy = fuzzy, true
+		}
+	}
+
+	result.Facets = facets
+
+	for _, facet := range facets {
+		if itemType == "" || facet.Type == itemType {
+			result.Total += facet.Count
+		}
+	}
+
+	if result.Total == 0 {
+		return result, nil
+	}
+
+	result.Hits, err = i.searchPage(result.Fuzzy, locale, query, now, itemType, nil, offset, limit)
+
+	return result, err
+}
+
+// SearchAfter is Search with keyset pagination: it returns up to limit hits
+// after the cursor and counts neither the total nor the facets. The first
+// page falls back to similar titles like Search, judged by its own hits; the
+// cursor keeps the later pages on the same matcher.
+func (i *Instance) SearchAfter(locale, query string, now time.Time, itemType string, after *SearchCursor, limit int) (*SearchHits, error) {
+	var err error
+
+	result := &SearchHits{}
+
+	if after != nil {
+		result.Fuzzy = after.Fuzzy
+		result.Hits, err = i.searchPage(after.Fuzzy, locale, query, now, itemType, after, 0, limit)
+
+		return result, err
+	}
+
+	if result.Hits, err = i.searchPage(false, locale, query, now, itemType, nil, 0, limit); err != nil {
+		return nil, err
+	}
+
+	exact := matchStats{Count: int64(len(result.Hits))}
+	if exact.Count > 0 {
+		exact.Rank = result.Hits[0].Rank
+	}
+
+	if !exact.weak() {
+		return result, nil
+	}
+
+	fuzzy, err := i.searchPage(true, locale, query, now, itemType, nil, 0, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fuzzy) > 0 {
+		result.Hits, result.Fuzzy = fuzzy, true
+	}
+
+	return result, nil
+}
+
+// SearchCursor is the key of the last hit of a keyset search page; Fuzzy
+// keeps the later pages on the matcher of the first one.
+type SearchCursor struct {
+	Rank  float64 `json:"r"`
+	Type  string  `json:"t"`
+	ID    uint    `json:"i"`
+	Fuzzy bool    `json:"f,omitempty"`
+}
+
+// hitsAfter orders hits by relevance, with type and id as tie-breakers, and
+// keeps only the hits that come after the cursor.
+func hitsAfter(after *SearchCursor) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Order("hits.rank DESC, hits.type ASC, hits.id DESC")
+
+		if after == nil || after.ID == 0 {
+			return db
+		}
+
+		return db.Where(
+			"hits.rank < ? OR (hits.rank = ? AND (hits.type > ? OR (hits.type = ? AND hits.id < ?)))",
+			after.Rank, after.Rank, after.Type, after.Type, after.ID)
+	}
+}
+
+// searchPage loads one page of hits, of itemType when it is set, in the
+// order of hitsAfter. A fuzzy match is on the title, so there is nothing to
+// highlight and the snippet stays empty.
+func (i *Instance) searchPage(fuzzy bool, locale, query string, now time.Time, itemType string, after *SearchCursor, offset, limit int) ([]SearchHit, error) {
+	var hits []SearchHit
+
+	err := i.searchPageQuery(fuzzy, locale, query, now, itemType, after, offset, limit).
+		Scan(&hits).Error
+
+	for idx := range hits {
+		hits[idx].Snippet = highlightSnippet(hits[idx].Snippet)
+	}
+
+	return hits, err
+}
+
+// searchPageQuery builds the query of searchPage. Only searchSnippet takes
+// named arguments; the fuzzy query must not get them, since every argument
+// becomes a bind parameter whether or not the SQL uses it.
+func (i *Instance) searchPageQuery(fuzzy bool, locale, query string, now time.Time, itemType string, after *SearchCursor, offset, limit int) *gorm.DB {
+	var db *gorm.DB
+
+	if fuzzy {
+		db = i.searchHits(i.fuzzyMatches, locale, query, now).
+			Select("hits.type, hits.id, hits.rank, '' AS snippet")
+	} else {
+		db = i.searchHits(i.searchMatches, locale, query, now).
+			Select("hits.type, hits.id, hits.rank, "+searchSnippet,
+				sql.Named("locale", locale),
+				sql.Named("query", strings.TrimSpace(query)),
+				sql.Named("snippet_options", snippetOptions),
+				sql.Named("title_options", titleSnippetOptions),
+			)
+	}
+
+	if itemType != "" {
+		db = db.Where("hits.type = ?", itemType)
+	}
+
+	return db.
+		Debug().
+		Scopes(hitsAfter(after)).
+		Offset(offset).
+		Limit(limit)
+}
+
+// selectedStats sums the facets of itemType, or of every type when it is
+// empty.
+func selectedStats(facets []SearchFacet, itemType string) matchStats {
+	var stats matchStats
+
+	for _, facet := range facets {
+		if itemType == "" || facet.Type == itemType {
+			stats.Count += int64(facet.Count)
+			stats.Rank = max(stats.Rank, facet.Rank)
+		}
+	}
+
+	return stats
+}
+
+// searchFacets counts the matches of each type.
+func (i *Instance) searchFacets(match matcher, locale, query string, now time.Time) ([]SearchFacet, error) {
+	var facets []SearchFacet
+
+	err := i.searchHits(match, locale, query, now).
+		Debug().
+		Select("hits.type, COUNT(*) AS count, MAX(hits.rank) AS rank").
+		Group("hits.type").
+		Order("hits.type ASC").
+		Scan(&facets).Error
+
+	return facets, err
+}
+
+// GetNewsByIDs loads news items for search results; the result is not in ids order.
+func (i *Instance) GetNewsByIDs(ids []uint, locale string) ([]models.News, error) {
+	var news []models.News
+
+	err := i.db.
+		Debug().
+		Preload("Translations", "language_code = ?", locale).
+		Where("news_id IN ?", ids).
+		Find(&news).Error
+
+	return news, err
+}
+
+// GetPagesByIDs loads pages for search results; the result is not in ids order.
+func (i *Instance) GetPagesByIDs(ids []uint, locale string) ([]models.Page, error) {
+	var pages []models.Page
+
+	err := i.db.
+		Debug().
+		Preload("Translations", "language_code = ?", locale).
+		Where("page_id IN ?", ids).
+		Find(&pages).Error
+
+	return pages, err
+}
+
+// GetDocumentsByIDs loads documents for search results; the result is not in ids order.
+func (i *Instance) GetDocumentsByIDs(ids []uint, locale string) ([]models.Document, error) {
+	var documents []models.Document
+
+	err := i.db.
+		Debug().
+		Preload("Translations", "language_code = ?", locale).
+		Where("document_id IN ?", ids).
+		Find(&documents).Error
+
+	return documents, err
+}
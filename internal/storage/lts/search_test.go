@@ -0,0 +1,82 @@
+package lts
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHighlightSnippet(t *testing.T) {
+	tests := []struct {
+		name    string
+		snippet string
+		want    string
+	}{
+		{"plain text", "Гидравлический пресс", "Гидравлический пресс"},
+		{"one match", "Гидравлический " + snippetStart + "пресс" + snippetStop, "Гидравлический <mark>пресс</mark>"},
+		{
+			"several matches",
+			snippetStart + "press" + snippetStop + " and " + snippetStart + "pump" + snippetStop,
+			"<mark>press</mark> and <mark>pump</mark>",
+		},
+		{"markup is escaped", `<script>alert("x")</script>`, "&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;"},
+		{
+			"escaped around a match",
+			"a & " + snippetStart + "b<c" + snippetStop,
+			"a &amp; <mark>b&lt;c</mark>",
+		},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := highlightSnippet(tt.snippet); got != tt.want {
+				t.Errorf("highlightSnippet(%q) = %q, want %q", tt.snippet, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchPageQuery(t *testing.T) {
+	i := dryRunInstance(t)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	after := &SearchCursor{Rank: 0.5, Type: "product", ID: 42}
+
+	tests := []struct {
+		name        string
+		fuzzy       bool
+		itemType    string
+		after       *SearchCursor
+		wantSnippet bool
+	}{
+		{name: "full text", wantSnippet: true},
+		{name: "full text of one type after a cursor", itemType: "news", after: after, wantSnippet: true},
+		{name: "fuzzy", fuzzy: true},
+		{name: "fuzzy of one type after a cursor", fuzzy: true, itemType: "product", after: after},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hits []SearchHit
+
+			stmt := i.searchPageQuery(tt.fuzzy, "pl", "pompa", now, tt.itemType, tt.after, 0, 20).Find(&hits).Statement
+			checkBindParameters(t, stmt)
+
+			sql := stmt.SQL.String()
+			if got := strings.Contains(sql, "ts_headline"); got != tt.wantSnippet {
+				t.Errorf("ts_headline in SQL = %v, want %v: %s", got, tt.wantSnippet, sql)
+			}
+
+			var snippetArgs int
+			for _, v := range stmt.Vars {
+				if v == snippetOptions || v == titleSnippetOptions {
+					snippetArgs++
+				}
+			}
+
+			if want := map[bool]int{true: 2, false: 0}[tt.wantSnippet]; snippetArgs != want {
+				t.Errorf("got %d snippet option arguments, want %d", snippetArgs, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package lts
+
+import (
+	"database/sql"
+	"international_site/internal/storage/models"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Ranks of SKU matches in search. They start above skuRankBase, far above
+// any ts_rank of a text match, so a SKU hit always comes first.
+const (
+	skuRankBase       = 10.0
+	skuRankExact      = skuRankBase + 4
+	skuRankNormalized = skuRankBase + 3
+	skuRankPrefix     = skuRankBase + 2
+	skuRankPartial    = skuRankBase + 1
+)
+
+// productRank is the relevance of a product in searchedProducts: the better
+// of its text and SKU matches.
+const productRank = "GREATEST(COALESCE(matches.rank, 0), COALESCE(skus.rank, 0))"
+
+// skuKey normalizes a SKU the way the sku_key SQL function does.
+func skuKey(sku string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+
+		return -1
+	}, strings.ToLower(sku))
+}
+
+// skuMatches selects sku_id and rank for the products whose SKU matches the
+// query exactly, after normalization or by prefix, or, when the query has a
+// digit and so looks like a SKU, anywhere in the SKU: "H200" finds
+// "PRESS-H200". A variant counts as a match of its parent.
+func (i *Instance) skuMatches(query string) *gorm.DB {
+	query = strings.TrimSpace(query)
+	key := skuKey(query)
+	escaped := likeEscaper.Replace(key)
+
+	pattern := escaped + "%"
+	if strings.ContainsFunc(key, unicode.IsDigit) {
+		pattern = "%" + escaped + "%"
+	}
+
+	args := []any{
+		sql.Named("sku", query),
+		sql.Named("key", key),
+		sql.Named("prefix", escaped+"%"),
+		sql.Named("pattern", pattern),
+		sql.Named("exact", skuRankExact),
+		sql.Named("normalized", skuRankNormalized),
+		sql.Named("prefix_rank", skuRankPrefix),
+		sql.Named("partial", skuRankPartial),
+	}
+
+	db := i.db.Model(&models.Product{}).
+		Select(`COALESCE(products.parent_id, products.product_id) AS sku_id, MAX(CASE
+			WHEN LOWER(products.sku) = LOWER(@sku) THEN CAST(@exact AS double precision)
+			WHEN sku_key(products.sku) = @key THEN CAST(@normalized AS double precision)
+			WHEN sku_key(products.sku) LIKE @prefix THEN CAST(@prefix_rank AS double precision)
+			ELSE CAST(@partial AS double precision)
+		END) AS rank`, args...).
+		Scopes(published("products")).
+		Group("COALESCE(products.parent_id, products.product_id)")
+
+	if key == "" {
+		return db.Where("LOWER(products.sku) = LOWER(@sku)", args...)
+	}
+
+	return db.Where("LOWER(products.sku) = LOWER(@sku) OR sku_key(products.sku) LIKE @pattern", args...)
+}
+
+// GetProductBySKU returns the published product or variant whose sku_key
+// equals that of sku, so case, dashes and spaces are ignored. An exact
+// spelling wins.
+func (i *Instance) GetProductBySKU(sku string) (*models.Product, error) {
+	var product models.Product
+
+	sku = strings.TrimSpace(sku)
+
+	key := skuKey(sku)
+	if key == "" {
+		return nil, ErrNotFound
+	}
+
+	err := i.db.
+		Debug().
+		Scopes(published("products")).
+		Where("sku_key(sku) = ?", key).
+		Order(clause.OrderBy{Expression: gorm.Expr("LOWER(sku) = LOWER(?) DESC, product_id ASC", sku)}).
+		First(&product).Error
+
+	return &product, translateError(err)
+}
@@ -0,0 +1,26 @@
+package lts
+
+import "testing"
+
+func TestSkuKey(t *testing.T) {
+	tests := []struct {
+		sku  string
+		want string
+	}{
+		{"PRESS-H200", "pressh200"},
+		{"press h200", "pressh200"},
+		{"PRESSH200", "pressh200"},
+		{" ГП-100/А ", "гп100а"},
+		{"A.B_C", "abc"},
+		{"-/ ", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sku, func(t *testing.T) {
+			if got := skuKey(tt.sku); got != tt.want {
+				t.Errorf("skuKey(%q) = %q, want %q", tt.sku, got, tt.want)
+			}
+		})
+	}
+}
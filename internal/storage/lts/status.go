@@ -0,0 +1,29 @@
+package lts
+
+import (
+	"international_site/internal/storage/models"
+
+	"gorm.io/gorm"
+)
+
+// published keeps the rows of table that the public site may show.
+func published(table string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(table+".status = ?", models.StatusPublished)
+	}
+}
+
+// SetStatus moves a product, page, news item or document, given as a model
+// with its ID set, to another publication status.
+func (i *Instance) SetStatus(item any, status string) error {
+	res := i.db.Debug().Model(item).Update("status", status)
+	if res.Error != nil {
+		return translateError(res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package lts
+
+import (
+	"international_site/internal/storage/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (i *Instance) GetWarehouses() ([]models.Warehouse, error) {
+	var warehouses []models.Warehouse
+
+	err := i.db.
+		Debug().
+		Order("sort_order ASC").
+		Find(&warehouses).Error
+
+	return warehouses, err
+}
+
+// GetProductStock returns the stock of a product per warehouse, or
+// ErrNotFound when the product does not exist.
+func (i *Instance) GetProductStock(productID uint) ([]models.ProductStock, error) {
+	var product models.Product
+
+	err := i.db.
+		Debug().
+		Select("product_id").
+		Preload("Stock", func(db *gorm.DB) *gorm.DB { return db.Order("warehouse_id ASC") }).
+		Preload("Stock.Warehouse").
+		Where("product_id = ?", productID).
+		First(&product).Error
+
+	return product.Stock, translateError(err)
+}
+
+// GetProductIDsBySKU maps each existing SKU from skus to its product id.
+func (i *Instance) GetProductIDsBySKU(skus []string) (map[string]uint, error) {
+	var products []models.Product
+
+	err := i.db.
+		Debug().
+		Select("product_id", "sku").
+		Where("sku IN ?", skus).
+		Find(&products).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]uint, len(products))
+	for _, product := range products {
+		ids[product.SKU] = product.ID
+	}
+
+	return ids, nil
+}
+
+// UpsertStock writes all stock rows in one statement, overwriting existing
+// quantities for the same product and warehouse.
+func (i *Instance) UpsertStock(stock []models.ProductStock) error {
+	err := i.db.
+		Debug().
+		Omit(clause.Associations).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "product_id"}, {Name: "warehouse_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"quantity", "reserved", "updated_at"}),
+		}).
+		Create(&stock).Error
+
+	return translateError(err)
+}
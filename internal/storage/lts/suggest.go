@@ -0,0 +1,82 @@
+package lts
+
+import (
+	"database/sql"
+	"international_site/internal/storage/models"
+	"regexp"
+	"strings"
+)
+
+// suggestQuery completes what the visitor has typed so far: product, category
+// and page titles that start with it or have a word starting with it, and SKUs
+// starting with it. A word starts after a space, a dash or a slash. Each group
+// is limited on its own and titles starting with the text come first. The
+// trigram indexes on LOWER(...) serve both the LIKE and the regex pattern.
+const suggestQuery = `(
+	SELECT 'product' AS type, p.product_id AS id, t.name AS title, p.sku AS sku, '' AS slug
+	FROM product_translations t
+	JOIN products p ON p.product_id = t.product_id
+	WHERE t.language_code = @locale
+		AND p.parent_id IS NULL
+		AND p.status = @published
+		AND LOWER(t.name) ~ @word
+	ORDER BY LOWER(t.name) LIKE @prefix DESC, LENGTH(t.name), t.name
+	LIMIT @limit
+) UNION ALL (
+	SELECT 'sku', p.product_id, COALESCE(t.name, p.sku), p.sku, ''
+	FROM products p
+	LEFT JOIN product_translations t ON t.product_id = p.product_id AND t.language_code = @locale
+	WHERE p.status = @published
+		AND LOWER(p.sku) LIKE @prefix
+	ORDER BY LENGTH(p.sku), p.sku
+	LIMIT @limit
+) UNION ALL (
+	SELECT 'category', c.category_id, c.name, '', c.slug
+	FROM product_category_translations c
+	WHERE c.language_code = @locale
+		AND LOWER(c.name) ~ @word
+	ORDER BY LOWER(c.name) LIKE @prefix DESC, LENGTH(c.name), c.name
+	LIMIT @limit
+) UNION ALL (
+	SELECT 'page', pg.page_id, t.title, '', pg.slug
+	FROM page_translations t
+	JOIN pages pg ON pg.page_id = t.page_id
+	WHERE t.language_code = @locale
+		AND pg.status = @published
+		AND LOWER(t.title) ~ @word
+	ORDER BY LOWER(t.title) LIKE @prefix DESC, LENGTH(t.title), t.title
+	LIMIT @limit
+)`
+
+// SuggestHit is a row of suggestQuery; Type is product, sku, category or page.
+type SuggestHit struct {
+	Type  string
+	ID    uint
+	Title string
+	SKU   string
+	Slug  string
+}
+
+// likeEscaper makes user input literal inside a LIKE pattern.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Suggest returns up to limit completions of text per type: product, sku,
+// category and page.
+func (i *Instance) Suggest(locale, text string, limit int) ([]SuggestHit, error) {
+	var hits []SuggestHit
+
+	text = strings.ToLower(text)
+
+	err := i.db.
+		Debug().
+		Raw(suggestQuery,
+			sql.Named("locale", locale),
+			sql.Named("published", models.StatusPublished),
+			sql.Named("prefix", likeEscaper.Replace(text)+"%"),
+			sql.Named("word", `(^|[[:space:]/-])`+regexp.QuoteMeta(text)),
+			sql.Named("limit", limit),
+		).
+		Scan(&hits).Error
+
+	return hits, err
+}
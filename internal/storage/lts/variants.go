@@ -0,0 +1,146 @@
+package lts
+
+import (
+	"international_site/internal/storage/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// parentProducts hides variants from product listings; they are only shown
+// inside their parent.
+func parentProducts(db *gorm.DB) *gorm.DB {
+	return db.Where("products.parent_id IS NULL")
+}
+
+// selfOrParentOf matches products selected by subQuery directly or through
+// one of their variants.
+func selfOrParentOf(db *gorm.DB, subQuery any) *gorm.DB {
+	return db.Where("products.product_id IN (?) OR products.product_id IN (SELECT v.parent_id FROM products v WHERE v.product_id IN (?))",
+		subQuery, subQuery)
+}
+
+// variantParentsBySKU selects the parents of variants with the given SKU.
+func (i *Instance) variantParentsBySKU(sku string) *gorm.DB {
+	return i.db.Model(&models.Product{}).
+		Select("parent_id").
+		Where("parent_id IS NOT NULL AND LOWER(sku) = LOWER(?)", sku)
+}
+
+// byOrder sorts an Options, Values or Variants preload.
+func byOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("sort_order ASC")
+}
+
+// SetProductOptions replaces the option axes of a product. Options and values
+// are matched by code so existing variants keep their selections; variant
+// selections of removed values are dropped by ON DELETE CASCADE, so the
+// service does not change the axes of a product that has variants.
+func (i *Instance) SetProductOptions(productID uint, options []models.ProductOption) error {
+	err := i.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Debug().Model(&models.Product{}).Where("product_id = ?", productID).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count == 0 {
+			return ErrNotFound
+		}
+
+		var existing []models.ProductOption
+		if err := tx.Debug().Preload("Values").Where("product_id = ?", productID).Find(&existing).Error; err != nil {
+			return err
+		}
+
+		existingOptions := make(map[string]models.ProductOption, len(existing))
+		for _, option := range existing {
+			existingOptions[option.Code] = option
+		}
+
+		keptOptions := []uint{0}
+
+		for idx := range options {
+			option := &options[idx]
+			option.ProductID = productID
+
+			old, ok := existingOptions[option.Code]
+			if err := saveByCode(tx, option, &option.ID, old.ID, ok, "sort_order"); err != nil {
+				return err
+			}
+
+			keptOptions = append(keptOptions, option.ID)
+
+			if err := tx.Debug().Where("option_id = ?", option.ID).Delete(&models.ProductOptionTranslation{}).Error; err != nil {
+				return err
+			}
+
+			for j := range option.Translations {
+				option.Translations[j].OptionID = option.ID
+			}
+
+			if len(option.Translations) > 0 {
+				if err := tx.Debug().Create(&option.Translations).Error; err != nil {
+					return err
+				}
+			}
+
+			existingValues := make(map[string]uint, len(old.Values))
+			for _, value := range old.Values {
+				existingValues[value.Code] = value.ID
+			}
+
+			keptValues := []uint{0}
+
+			for j := range option.Values {
+				value := &option.Values[j]
+				value.OptionID = option.ID
+
+				oldID, ok := existingValues[value.Code]
+				if err := saveByCode(tx, value, &value.ID, oldID, ok, "sort_order"); err != nil {
+					return err
+				}
+
+				keptValues = append(keptValues, value.ID)
+
+				if err := tx.Debug().Where("value_id = ?", value.ID).Delete(&models.ProductOptionValueTranslation{}).Error; err != nil {
+					return err
+				}
+
+				for k := range value.Translations {
+					value.Translations[k].ValueID = value.ID
+				}
+
+				if len(value.Translations) > 0 {
+					if err := tx.Debug().Create(&value.Translations).Error; err != nil {
+						return err
+					}
+				}
+			}
+
+			err := tx.Debug().
+				Where("option_id = ? AND value_id NOT IN ?", option.ID, keptValues).
+				Delete(&models.ProductOptionValue{}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Debug().
+			Where("product_id = ? AND option_id NOT IN ?", productID, keptOptions).
+			Delete(&models.ProductOption{}).Error
+	})
+
+	return translateError(err)
+}
+
+// saveByCode updates the row identified by existingID when found is set and
+// inserts it otherwise; id receives the primary key either way.
+func saveByCode(tx *gorm.DB, row any, id *uint, existingID uint, found bool, columns ...string) error {
+	if !found {
+		return tx.Debug().Omit(clause.Associations).Create(row).Error
+	}
+
+	*id = existingID
+
+	return tx.Debug().Model(row).Select(columns).Updates(row).Error
+}
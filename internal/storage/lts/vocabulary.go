@@ -0,0 +1,83 @@
+package lts
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// closestWordsQuery picks, for every word of the query, the vocabulary word
+// most similar to it; the word itself wins when it is in the vocabulary, then
+// the more similar, then the more frequent one. The trigram index on word
+// serves %.
+const closestWordsQuery = `SELECT q.word AS word, c.word AS closest
+FROM unnest(CAST(@words AS text[])) AS q(word)
+CROSS JOIN LATERAL (
+	SELECT v.word
+	FROM search_vocabulary v
+	WHERE v.language_code = @locale AND v.word % q.word
+	ORDER BY v.word = q.word DESC, similarity(v.word, q.word) DESC, v.frequency DESC, v.word ASC
+	LIMIT 1
+) c`
+
+// GetClosestWords maps each of words, lowercased, to the closest word of the
+// product name and spec name vocabulary of locale. Words with nothing similar
+// enough are left out.
+func (i *Instance) GetClosestWords(locale string, words []string) (map[string]string, error) {
+	closest := make(map[string]string, len(words))
+	if len(words) == 0 {
+		return closest, nil
+	}
+
+	lower := make([]string, len(words))
+	for idx, word := range words {
+		lower[idx] = strings.ToLower(word)
+	}
+
+	var rows []struct {
+		Word    string
+		Closest string
+	}
+
+	err := i.closestWords(locale, lower).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		closest[row.Word] = row.Closest
+	}
+
+	return closest, nil
+}
+
+// closestWords builds the query of GetClosestWords; the words are bound as
+// one text[] parameter.
+func (i *Instance) closestWords(locale string, words []string) *gorm.DB {
+	return i.db.
+		Debug().
+		Raw(closestWordsQuery, sql.Named("locale", locale), sql.Named("words", textArray(words)))
+}
+
+// textArray binds a string slice as a single Postgres array literal; gorm
+// expands a plain slice into a list of parameters.
+type textArray []string
+
+var arrayElementEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func (a textArray) Value() (driver.Value, error) {
+	elements := make([]string, len(a))
+	for idx, element := range a {
+		elements[idx] = `"` + arrayElementEscaper.Replace(element) + `"`
+	}
+
+	return "{" + strings.Join(elements, ",") + "}", nil
+}
+
+// RefreshSearchVocabulary rebuilds the vocabulary from the current catalog.
+// CONCURRENTLY keeps it readable meanwhile; the unique index allows that.
+func (i *Instance) RefreshSearchVocabulary() error {
+	return i.db.Debug().Exec("REFRESH MATERIALIZED VIEW CONCURRENTLY search_vocabulary").Error
+}
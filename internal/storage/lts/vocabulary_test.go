@@ -0,0 +1,62 @@
+package lts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClosestWordsQuery(t *testing.T) {
+	i := dryRunInstance(t)
+
+	var rows []struct {
+		Word    string
+		Closest string
+	}
+
+	stmt := i.closestWords("pl", []string{"pompa", "hydrauliczna"}).Find(&rows).Statement
+	checkBindParameters(t, stmt)
+
+	if sql := stmt.SQL.String(); !strings.Contains(sql, "unnest(CAST($1 AS text[]))") || !strings.Contains(sql, "v.language_code = $2") {
+		t.Errorf("words and locale are not bound as $1 and $2: %s", sql)
+	}
+
+	if len(stmt.Vars) != 2 {
+		t.Fatalf("got %d arguments, want 2: %v", len(stmt.Vars), stmt.Vars)
+	}
+
+	words, ok := stmt.Vars[0].(textArray)
+	if !ok {
+		t.Fatalf("words are bound as %T, want one textArray", stmt.Vars[0])
+	}
+
+	if value, _ := words.Value(); value != `{"pompa","hydrauliczna"}` {
+		t.Errorf("words = %v, want {\"pompa\",\"hydrauliczna\"}", value)
+	}
+}
+
+func TestTextArray(t *testing.T) {
+	tests := []struct {
+		name  string
+		array textArray
+		want  string
+	}{
+		{"empty", textArray{}, "{}"},
+		{"words", textArray{"pompa", "насос"}, `{"pompa","насос"}`},
+		{"separators", textArray{"a,b", "{c}", "d e"}, `{"a,b","{c}","d e"}`},
+		{"quotes and backslashes", textArray{`12"`, `a\b`}, `{"12\"","a\\b"}`},
+		{"null word", textArray{"null"}, `{"null"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.array.Value()
+			if err != nil {
+				t.Fatalf("Value(): %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Value() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"encoding/json"
+	"international_site/pkg/money"
 	"time"
 )
 
@@ -10,14 +12,25 @@ type Language struct {
 	Name string `json:"name" gorm:"column:name;size:100"`
 }
 
+// Publication statuses of products, pages, news and documents; the public
+// site shows only published ones
+const (
+	StatusDraft     = "draft"
+	StatusInReview  = "in_review"
+	StatusPublished = "published"
+	StatusArchived  = "archived"
+)
+
 // Page - страница сайта
 type Page struct {
 	ID           uint              `json:"id" gorm:"column:page_id;primaryKey;autoIncrement"`
 	Slug         string            `json:"slug" gorm:"column:slug;uniqueIndex;size:255"`
 	Template     string            `json:"template" gorm:"column:template;size:100"`
+	Status       string            `json:"status" gorm:"column:status;size:20;default:draft"`
 	CreatedAt    time.Time         `json:"created_at" gorm:"column:created_at;autoCreateTime"`
 	UpdatedAt    time.Time         `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
 	Translations []PageTranslation `json:"translations" gorm:"foreignKey:PageID;references:ID"`
+	Rank         float64           `json:"rank,omitempty" gorm:"column:rank;->;-:migration"` // релевантность, только в поиске
 }
 
 // PageTranslation - переводы страниц
@@ -44,26 +57,57 @@ type ProductCategory struct {
 // ProductCategoryTranslation
 type ProductCategoryTranslation struct {
 	CategoryID   uint   `json:"category_id" gorm:"column:category_id;primaryKey"`
-	LanguageCode string `json:"language_code" gorm:"column:language_code;primaryKey;size:10"`
+	LanguageCode string `json:"language_code" gorm:"column:language_code;primaryKey;size:10;uniqueIndex:idx_category_translation_slug,priority:1"`
 	Name         string `json:"name" gorm:"column:name;size:255"`
 	Description  string `json:"description" gorm:"column:description;type:text"`
+	Slug         string `json:"slug" gorm:"column:slug;size:255;uniqueIndex:idx_category_translation_slug,priority:2"`
 }
 
 // Product - продукт
 type Product struct {
-	ID           uint                 `json:"id" gorm:"column:product_id;primaryKey;autoIncrement"`
-	CategoryID   uint                 `json:"category_id" gorm:"column:category_id;index"`
-	SKU          string               `json:"sku" gorm:"column:sku;uniqueIndex;size:100"`
-	ImageURL     string               `json:"image_url" gorm:"column:image_url;size:500"`
-	FileURL      string               `json:"file_url" gorm:"column:file_url;size:500"`
-	SortOrder    int                  `json:"sort_order" gorm:"column:sort_order;default:0"`
-	CreatedAt    time.Time            `json:"created_at" gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt    time.Time            `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
-	Category     ProductCategory      `json:"category" gorm:"foreignKey:CategoryID;references:ID"`
-	Translations []ProductTranslation `json:"translations" gorm:"foreignKey:ProductID;references:ID"`
-	Specs        []ProductSpec        `json:"specs" gorm:"foreignKey:ProductID;references:ID"`
+	ID             uint                  `json:"id" gorm:"column:product_id;primaryKey;autoIncrement"`
+	CategoryID     uint                  `json:"category_id" gorm:"column:category_id;index"`
+	ParentID       *uint                 `json:"parent_id" gorm:"column:parent_id;index"` // задан у вариантов конфигурируемого продукта
+	SKU            string                `json:"sku" gorm:"column:sku;uniqueIndex;size:100"`
+	ImageURL       string                `json:"image_url" gorm:"column:image_url;size:500"`
+	FileURL        string                `json:"file_url" gorm:"column:file_url;size:500"`
+	SortOrder      int                   `json:"sort_order" gorm:"column:sort_order;default:0"`
+	PriceOnRequest bool                  `json:"price_on_request" gorm:"column:price_on_request;default:false"`
+	LeadTimeDays   int                   `json:"lead_time_days" gorm:"column:lead_time_days;default:0"` // срок изготовления, если нет на складе
+	Status         string                `json:"status" gorm:"column:status;size:20;default:draft"`
+	CreatedAt      time.Time             `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt      time.Time             `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+	Category       ProductCategory       `json:"category" gorm:"foreignKey:CategoryID;references:ID"`
+	Translations   []ProductTranslation  `json:"translations" gorm:"foreignKey:ProductID;references:ID"`
+	Specs          []ProductSpec         `json:"specs" gorm:"foreignKey:ProductID;references:ID"`
+	Prices         []ProductPrice        `json:"prices" gorm:"foreignKey:ProductID;references:ID"`
+	Stock          []ProductStock        `json:"stock,omitempty" gorm:"foreignKey:ProductID;references:ID"` // только в админке
+	Media          []ProductMedia        `json:"media" gorm:"foreignKey:ProductID;references:ID"`
+	Links          []ProductLink         `json:"links,omitempty" gorm:"foreignKey:ProductID;references:ID"`
+	Options        []ProductOption       `json:"options,omitempty" gorm:"foreignKey:ProductID;references:ID"`
+	Variants       []Product             `json:"variants,omitempty" gorm:"foreignKey:ParentID;references:ID"`
+	VariantValues  []ProductVariantValue `json:"variant_values,omitempty" gorm:"foreignKey:ProductID;references:ID"`
+
+	// Availability, AvailableQuantity and StockLevel are derived from Stock and
+	// LeadTimeDays; the public API shows the quantity only bucketed as StockLevel
+	Availability      string `json:"availability" gorm:"-"`
+	AvailableQuantity int    `json:"-" gorm:"-"`
+	StockLevel        string `json:"stock_level,omitempty" gorm:"-"` // например "10+"
+
+	// PrimaryImageURL is the primary gallery image, or ImageURL when there is none
+	PrimaryImageURL string `json:"primary_image_url" gorm:"-"`
+
+	// Rank is the full-text relevance, filled only by search queries
+	Rank float64 `json:"rank,omitempty" gorm:"column:rank;->;-:migration"`
 }
 
+// Product availability statuses
+const (
+	AvailabilityInStock    = "in_stock"
+	AvailabilityOnOrder    = "on_order"
+	AvailabilityOutOfStock = "out_of_stock"
+)
+
 // ProductTranslation
 type ProductTranslation struct {
 	ProductID    uint   `json:"product_id" gorm:"column:product_id;primaryKey"`
@@ -75,12 +119,17 @@ type ProductTranslation struct {
 
 // ProductSpec - характеристики продукта
 type ProductSpec struct {
-	ID           uint                     `json:"id" gorm:"column:product_spec_id;primaryKey;autoIncrement"`
+	ID           uint                     `json:"id" gorm:"column:spec_id;primaryKey;autoIncrement"`
 	ProductID    uint                     `json:"product_id" gorm:"column:product_id;index"`
 	SortOrder    int                      `json:"sort_order" gorm:"column:sort_order;default:0"`
+	NumericValue *float64                 `json:"numeric_value" gorm:"column:numeric_value"` // в базовой единице измерения (kg, kW, mm)
+	Unit         string                   `json:"unit" gorm:"column:unit;size:20"`           // единица, в которой значение было введено
+	Dimension    string                   `json:"dimension" gorm:"column:dimension;size:20"` // mass, power, length
 	CreatedAt    time.Time                `json:"created_at" gorm:"column:created_at;autoCreateTime"`
 	Product      Product                  `json:"product" gorm:"foreignKey:ProductID;references:ID"`
 	Translations []ProductSpecTranslation `json:"translations" gorm:"foreignKey:SpecID;references:ID"`
+	DisplayValue *float64                 `json:"display_value,omitempty" gorm:"-"`
+	DisplayUnit  string                   `json:"display_unit,omitempty" gorm:"-"`
 }
 
 // ProductSpecTranslation
@@ -91,14 +140,142 @@ type ProductSpecTranslation struct {
 	Value        string `json:"value" gorm:"column:value;size:500"`
 }
 
+// ProductMedia - изображение, видео или файл продукта; с language_code - только для этого языка
+type ProductMedia struct {
+	ID           uint                      `json:"id" gorm:"column:media_id;primaryKey;autoIncrement"`
+	ProductID    uint                      `json:"product_id" gorm:"column:product_id;index"`
+	Kind         string                    `json:"kind" gorm:"column:kind;size:20"` // image, video, file
+	Type         string                    `json:"type" gorm:"column:type;size:50"` // manual, cad, brochure
+	URL          string                    `json:"url" gorm:"column:url;size:500"`
+	LanguageCode *string                   `json:"language_code" gorm:"column:language_code;size:10"`
+	IsPrimary    bool                      `json:"is_primary" gorm:"column:is_primary;default:false"`
+	SortOrder    int                       `json:"sort_order" gorm:"column:sort_order;default:0"`
+	CreatedAt    time.Time                 `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	Translations []ProductMediaTranslation `json:"translations" gorm:"foreignKey:MediaID;references:ID"`
+}
+
+// Product media kinds
+const (
+	MediaImage = "image"
+	MediaVideo = "video"
+	MediaFile  = "file"
+)
+
+// ProductMediaTranslation - подпись к медиа
+type ProductMediaTranslation struct {
+	MediaID      uint   `json:"media_id" gorm:"column:media_id;primaryKey"`
+	LanguageCode string `json:"language_code" gorm:"column:language_code;primaryKey;size:10"`
+	Caption      string `json:"caption" gorm:"column:caption;size:500"`
+}
+
+// ProductLink - ручная связь: аксессуар или замена
+type ProductLink struct {
+	ProductID       uint   `json:"product_id" gorm:"column:product_id;primaryKey"`
+	LinkedProductID uint   `json:"linked_product_id" gorm:"column:linked_product_id;primaryKey"`
+	LinkType        string `json:"link_type" gorm:"column:link_type;size:20"` // accessory, replacement
+	SortOrder       int    `json:"sort_order" gorm:"column:sort_order;default:0"`
+}
+
+// Product link types
+const (
+	LinkAccessory   = "accessory"
+	LinkReplacement = "replacement"
+)
+
+// ProductOption - ось конфигурации продукта (напряжение, размер стола, система ЧПУ)
+type ProductOption struct {
+	ID           uint                       `json:"id" gorm:"column:option_id;primaryKey;autoIncrement"`
+	ProductID    uint                       `json:"product_id" gorm:"column:product_id;index"`
+	Code         string                     `json:"code" gorm:"column:code;size:50"`
+	SortOrder    int                        `json:"sort_order" gorm:"column:sort_order;default:0"`
+	Translations []ProductOptionTranslation `json:"translations" gorm:"foreignKey:OptionID;references:ID"`
+	Values       []ProductOptionValue       `json:"values" gorm:"foreignKey:OptionID;references:ID"`
+}
+
+// ProductOptionTranslation
+type ProductOptionTranslation struct {
+	OptionID     uint   `json:"option_id" gorm:"column:option_id;primaryKey"`
+	LanguageCode string `json:"language_code" gorm:"column:language_code;primaryKey;size:10"`
+	Name         string `json:"name" gorm:"column:name;size:255"`
+}
+
+// ProductOptionValue - значение оси конфигурации
+type ProductOptionValue struct {
+	ID           uint                            `json:"id" gorm:"column:value_id;primaryKey;autoIncrement"`
+	OptionID     uint                            `json:"option_id" gorm:"column:option_id;index"`
+	Code         string                          `json:"code" gorm:"column:code;size:50"`
+	SortOrder    int                             `json:"sort_order" gorm:"column:sort_order;default:0"`
+	Translations []ProductOptionValueTranslation `json:"translations" gorm:"foreignKey:ValueID;references:ID"`
+}
+
+// ProductOptionValueTranslation
+type ProductOptionValueTranslation struct {
+	ValueID      uint   `json:"value_id" gorm:"column:value_id;primaryKey"`
+	LanguageCode string `json:"language_code" gorm:"column:language_code;primaryKey;size:10"`
+	Label        string `json:"label" gorm:"column:label;size:255"`
+}
+
+// ProductVariantValue - значение, выбранное вариантом по одной оси
+type ProductVariantValue struct {
+	ProductID uint `json:"product_id" gorm:"column:product_id;primaryKey"`
+	OptionID  uint `json:"option_id" gorm:"column:option_id;primaryKey"`
+	ValueID   uint `json:"value_id" gorm:"column:value_id"`
+}
+
+// PriceList - прайс-лист в одной валюте
+type PriceList struct {
+	ID        uint              `json:"id" gorm:"column:price_list_id;primaryKey;autoIncrement"`
+	Code      string            `json:"code" gorm:"column:code;uniqueIndex;size:50"`
+	Currency  string            `json:"currency" gorm:"column:currency;uniqueIndex;size:3"` // RUB, EUR, PLN
+	CreatedAt time.Time         `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	Locales   []PriceListLocale `json:"locales" gorm:"foreignKey:PriceListID;references:ID"`
+}
+
+// PriceListLocale - прайс-лист, который показывается для языка
+type PriceListLocale struct {
+	LanguageCode string `json:"language_code" gorm:"column:language_code;primaryKey;size:10"`
+	PriceListID  uint   `json:"price_list_id" gorm:"column:price_list_id"`
+}
+
+// ProductPrice - цена продукта; строки с min_quantity > 1 - оптовые ступени
+type ProductPrice struct {
+	PriceListID uint         `json:"price_list_id" gorm:"column:price_list_id;primaryKey"`
+	ProductID   uint         `json:"product_id" gorm:"column:product_id;primaryKey"`
+	MinQuantity int          `json:"min_quantity" gorm:"column:min_quantity;primaryKey;default:1"`
+	Amount      money.Amount `json:"amount" gorm:"column:amount;type:numeric(14,2)"`
+	Currency    string       `json:"currency" gorm:"->;column:currency"` // из price_lists, только для чтения
+}
+
+// Warehouse - склад
+type Warehouse struct {
+	ID        uint      `json:"id" gorm:"column:warehouse_id;primaryKey;autoIncrement"`
+	Code      string    `json:"code" gorm:"column:code;uniqueIndex;size:50"`
+	Name      string    `json:"name" gorm:"column:name;size:255"`
+	SortOrder int       `json:"sort_order" gorm:"column:sort_order;default:0"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
+// ProductStock - остаток продукта на складе; доступно = quantity - reserved
+type ProductStock struct {
+	ProductID   uint      `json:"product_id" gorm:"column:product_id;primaryKey"`
+	WarehouseID uint      `json:"warehouse_id" gorm:"column:warehouse_id;primaryKey"`
+	Quantity    int       `json:"quantity" gorm:"column:quantity;default:0"`
+	Reserved    int       `json:"reserved" gorm:"column:reserved;default:0"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
+	Warehouse   Warehouse `json:"warehouse" gorm:"foreignKey:WarehouseID;references:ID"`
+}
+
 // News - новости
 type News struct {
 	ID           uint              `json:"id" gorm:"column:news_id;primaryKey;autoIncrement"`
 	ImageURL     string            `json:"image_url" gorm:"column:image_url;size:500"`
-	Published    bool              `json:"published" gorm:"column:published;default:false"`
+	Status       string            `json:"status" gorm:"column:status;size:20;default:draft"`
+	PublishAt    *time.Time        `json:"publish_at" gorm:"column:publish_at"`     // опубликованная новость видна с этого момента
+	UnpublishAt  *time.Time        `json:"unpublish_at" gorm:"column:unpublish_at"` // и скрывается с этого
 	CreatedAt    time.Time         `json:"created_at" gorm:"column:created_at;autoCreateTime"`
 	UpdatedAt    time.Time         `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
 	Translations []NewsTranslation `json:"translations" gorm:"foreignKey:NewsID;references:ID"`
+	Rank         float64           `json:"rank,omitempty" gorm:"column:rank;->;-:migration"` // релевантность, только в поиске
 }
 
 // NewsTranslation
@@ -115,8 +292,10 @@ type Document struct {
 	ID           uint                  `json:"id" gorm:"column:document_id;primaryKey;autoIncrement"`
 	FileURL      string                `json:"file_url" gorm:"column:file_url;size:500"`
 	Type         string                `json:"type" gorm:"column:type;size:50"` // gost, certificate, reference
+	Status       string                `json:"status" gorm:"column:status;size:20;default:draft"`
 	CreatedAt    time.Time             `json:"created_at" gorm:"column:created_at;autoCreateTime"`
 	Translations []DocumentTranslation `json:"translations" gorm:"foreignKey:DocumentID;references:ID"`
+	Rank         float64               `json:"rank,omitempty" gorm:"column:rank;->;-:migration"` // релевантность, только в поиске
 }
 
 // DocumentTranslation
@@ -144,6 +323,17 @@ type ContactTranslation struct {
 	Label        string `json:"label" gorm:"column:label;size:255"` // например "Главный офис"
 }
 
+// Revision - снимок страницы, продукта или новости после изменения в админке
+type Revision struct {
+	ID         uint            `json:"id" gorm:"column:revision_id;primaryKey;autoIncrement"`
+	EntityType string          `json:"entity_type" gorm:"column:entity_type;size:20"` // page, product, news
+	EntityID   uint            `json:"entity_id" gorm:"column:entity_id"`
+	Author     string          `json:"author" gorm:"column:author;size:100"`
+	Snapshot   json.RawMessage `json:"snapshot,omitempty" gorm:"column:snapshot;type:jsonb"`
+	Diff       json.RawMessage `json:"diff" gorm:"column:diff;type:jsonb"` // []types.FieldChange относительно предыдущей ревизии
+	CreatedAt  time.Time       `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+}
+
 // Feedback - форма обратной связи
 type Feedback struct {
 	ID        uint      `json:"id" gorm:"column:feedback_id;primaryKey;autoIncrement"`
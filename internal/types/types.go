@@ -2,6 +2,7 @@ package types
 
 import (
 	"international_site/internal/storage/models"
+	"international_site/pkg/money"
 	"time"
 )
 
@@ -30,12 +31,15 @@ type ProductDTO struct {
 }
 
 type CategoryDTO struct {
-	ID           uint   `json:"id"`
-	Name         string `json:"name"`
-	Description  string `json:"description"`
-	Slug         string `json:"slug"`
-	ParentID     *uint  `json:"parent_id"`
-	ProductCount int    `json:"product_count"`
+	ID                uint          `json:"id"`
+	Name              string        `json:"name"`
+	Description       string        `json:"description"`
+	Slug              string        `json:"slug"`
+	ParentID          *uint         `json:"parent_id"`
+	SortOrder         int           `json:"sort_order"`
+	ProductCount      int           `json:"product_count"`
+	TotalProductCount int           `json:"total_product_count"`
+	Children          []CategoryDTO `json:"children,omitempty"`
 }
 
 type NewsDTO struct {
@@ -72,16 +76,373 @@ type FeedbackRequest struct {
 }
 
 type SearchResult struct {
-	Type        string `json:"type"`
-	ID          uint   `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	URL         string `json:"url"`
-	Image       string `json:"image,omitempty"`
-	Date        string `json:"date,omitempty"`
+	Type        string  `json:"type"`
+	ID          uint    `json:"id"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	URL         string  `json:"url"`
+	Image       string  `json:"image,omitempty"`
+	Date        string  `json:"date,omitempty"`
+	Score       float64 `json:"score"`   // релевантность ts_rank; результаты отсортированы по ней
+	Snippet     string  `json:"snippet"` // фрагмент с совпадениями в <mark>, HTML-экранирован
+}
+
+// SearchResponse - страница общего поиска; facets считаются без учёта фильтра type
+type SearchResponse struct {
+	Query      string         `json:"query"`
+	Type       string         `json:"type,omitempty"`
+	Results    []SearchResult `json:"results"`
+	Total      int            `json:"total"`
+	Offset     int            `json:"offset"`
+	Limit      int            `json:"limit"`
+	Facets     []SearchFacet  `json:"facets"`
+	Redirect   string         `json:"redirect,omitempty"`     // ссылка на продукт, если запрос совпал с его артикулом
+	Fuzzy      bool           `json:"fuzzy,omitempty"`        // полнотекстовый поиск ничего не нашёл, найдено по похожести названий
+	DidYouMean string         `json:"did_you_mean,omitempty"` // исправленный запрос по словарю названий продуктов и характеристик
+	NextCursor string         `json:"next_cursor,omitempty"`  // только при постраничном выводе по ключу; total и facets тогда не считаются
+}
+
+type SearchFacet struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
+
+// Suggestion - подсказка строки поиска; url ведёт прямо на страницу
+type Suggestion struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+	SKU   string `json:"sku,omitempty"`
+	URL   string `json:"url"`
+}
+
+// Suggestions - подсказки по мере ввода, сгруппированные по типу
+type Suggestions struct {
+	Query      string       `json:"query"`
+	Products   []Suggestion `json:"products"`
+	SKUs       []Suggestion `json:"skus"`
+	Categories []Suggestion `json:"categories"`
+	Pages      []Suggestion `json:"pages"`
 }
 
 type SpecDTO struct {
 	Name  string `json:"name"`
 	Value string `json:"value"`
 }
+
+// RelatedProduct - продукт из блока «похожие» с его оценкой; Relation задан для ручных связей
+type RelatedProduct struct {
+	models.Product
+	Score    float64 `json:"score"`
+	Relation string  `json:"relation,omitempty"`
+}
+
+// Datasheet - PDF-паспорт продукта; ETag меняется вместе с UpdatedAt продукта
+type Datasheet struct {
+	Content    []byte
+	FileName   string
+	ModifiedAt time.Time
+	ETag       string
+}
+
+// StatusRequest - смена статуса публикации
+type StatusRequest struct {
+	Status string `json:"status" binding:"required"`
+}
+
+// NewsScheduleRequest - окно публикации новости; пустая граница снимает ограничение
+type NewsScheduleRequest struct {
+	PublishAt   *time.Time `json:"publish_at"`
+	UnpublishAt *time.Time `json:"unpublish_at"`
+}
+
+// Preview - подписанная ссылка на просмотр неопубликованного материала
+type Preview struct {
+	Token     string    `json:"token"`
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Breadcrumb - звено навигационной цепочки от главной страницы до текущей
+type Breadcrumb struct {
+	Type string `json:"type"` // home, products, news, category, product, page
+	ID   uint   `json:"id,omitempty"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// ProductDetail - продукт вместе с навигационной цепочкой
+type ProductDetail struct {
+	*models.Product
+	Breadcrumbs []Breadcrumb `json:"breadcrumbs"`
+}
+
+// ProductComparison - таблица сравнения: строки - объединение характеристик, столбцы - продукты
+type ProductComparison struct {
+	Products []ComparedProduct `json:"products"`
+	Rows     []ComparisonRow   `json:"rows"`
+}
+
+type ComparedProduct struct {
+	ID           uint   `json:"id"`
+	SKU          string `json:"sku"`
+	Name         string `json:"name"`
+	CategoryName string `json:"category_name"`
+	ImageURL     string `json:"image_url"`
+	Availability string `json:"availability"`
+}
+
+// ComparisonRow - Values follows the order of Products; nil where a product lacks the spec
+type ComparisonRow struct {
+	Name    string             `json:"name"`
+	Values  []*ComparisonValue `json:"values"`
+	Differs bool               `json:"differs"`
+}
+
+type ComparisonValue struct {
+	Value        string   `json:"value"`
+	DisplayValue *float64 `json:"display_value,omitempty"`
+	DisplayUnit  string   `json:"display_unit,omitempty"`
+}
+
+// ProductRequest - тело запроса на создание/изменение продукта в админке
+type ProductRequest struct {
+	CategoryID     uint                        `json:"category_id" binding:"required"`
+	SKU            string                      `json:"sku" binding:"required"`
+	ImageURL       string                      `json:"image_url"`
+	FileURL        string                      `json:"file_url"`
+	SortOrder      int                         `json:"sort_order"`
+	PriceOnRequest bool                        `json:"price_on_request"`
+	LeadTimeDays   int                         `json:"lead_time_days" binding:"min=0"`
+	Translations   []ProductTranslationRequest `json:"translations" binding:"required,dive"`
+	Specs          []ProductSpecRequest        `json:"specs" binding:"dive"`
+	Prices         []PriceRequest              `json:"prices" binding:"dive"`
+	Media          []ProductMediaRequest       `json:"media" binding:"dive"`
+	Links          []ProductLinkRequest        `json:"links" binding:"dive"`
+
+	// ParentID makes the product a variant; OptionValues then maps every
+	// option code of the parent to the value code this variant represents.
+	ParentID     *uint             `json:"parent_id"`
+	OptionValues map[string]string `json:"option_values"`
+}
+
+type ProductTranslationRequest struct {
+	LanguageCode     string `json:"language_code" binding:"required"`
+	Name             string `json:"name" binding:"required"`
+	Description      string `json:"description"`
+	ShortDescription string `json:"short_description"`
+}
+
+// ProductSpecRequest - numeric_value/unit are parsed from the translated values when omitted
+type ProductSpecRequest struct {
+	SortOrder    int                      `json:"sort_order"`
+	NumericValue *float64                 `json:"numeric_value"`
+	Unit         string                   `json:"unit"`
+	Translations []SpecTranslationRequest `json:"translations" binding:"required,dive"`
+}
+
+// ProductMediaRequest - language_code makes the item visible only in that language
+type ProductMediaRequest struct {
+	Kind         string                    `json:"kind" binding:"required,oneof=image video file"`
+	Type         string                    `json:"type"`
+	URL          string                    `json:"url" binding:"required"`
+	LanguageCode string                    `json:"language_code"`
+	IsPrimary    bool                      `json:"is_primary"`
+	SortOrder    int                       `json:"sort_order"`
+	Translations []MediaTranslationRequest `json:"translations" binding:"dive"`
+}
+
+type MediaTranslationRequest struct {
+	LanguageCode string `json:"language_code" binding:"required"`
+	Caption      string `json:"caption"`
+}
+
+// ProductLinkRequest - curated accessory or replacement shown first among related products
+type ProductLinkRequest struct {
+	ProductID uint   `json:"product_id" binding:"required"`
+	Type      string `json:"type" binding:"required,oneof=accessory replacement"`
+	SortOrder int    `json:"sort_order"`
+}
+
+// ImportReport - результат (или, при dry_run, план) импорта каталога
+type ImportReport struct {
+	DryRun    bool              `json:"dry_run"`
+	Applied   bool              `json:"applied"`
+	Created   int               `json:"created"`
+	Updated   int               `json:"updated"`
+	Unchanged int               `json:"unchanged"`
+	Errors    []string          `json:"errors,omitempty"` // ошибки файла целиком, например неизвестные столбцы
+	Rows      []ImportRowResult `json:"rows"`
+}
+
+// HasErrors reports whether the file or any of its rows failed validation.
+func (r *ImportReport) HasErrors() bool {
+	if len(r.Errors) > 0 {
+		return true
+	}
+
+	for _, row := range r.Rows {
+		if len(row.Errors) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ImportRowResult - Row is the spreadsheet row number, the header being row 1
+type ImportRowResult struct {
+	Row     int           `json:"row"`
+	SKU     string        `json:"sku"`
+	Action  string        `json:"action"` // create, update, unchanged, error
+	Changes []FieldChange `json:"changes,omitempty"`
+	Errors  []string      `json:"errors,omitempty"`
+}
+
+type FieldChange struct {
+	Column string `json:"column"`
+	Old    string `json:"old"`
+	New    string `json:"new"`
+}
+
+// ProductOptionsRequest replaces the option axes of a configurable product
+type ProductOptionsRequest struct {
+	Options []ProductOptionRequest `json:"options" binding:"dive"`
+}
+
+type ProductOptionRequest struct {
+	Code         string                     `json:"code" binding:"required"`
+	SortOrder    int                        `json:"sort_order"`
+	Translations []OptionTranslationRequest `json:"translations" binding:"required,dive"`
+	Values       []OptionValueRequest       `json:"values" binding:"required,dive"`
+}
+
+type OptionValueRequest struct {
+	Code         string                     `json:"code" binding:"required"`
+	SortOrder    int                        `json:"sort_order"`
+	Translations []OptionTranslationRequest `json:"translations" binding:"required,dive"`
+}
+
+// OptionTranslationRequest - name of an option or label of an option value
+type OptionTranslationRequest struct {
+	LanguageCode string `json:"language_code" binding:"required"`
+	Name         string `json:"name" binding:"required"`
+}
+
+// PriceRequest - цена в валюте прайс-листа; min_quantity > 1 задаёт оптовую ступень
+type PriceRequest struct {
+	Currency    string       `json:"currency" binding:"required"`
+	MinQuantity int          `json:"min_quantity"`
+	Amount      money.Amount `json:"amount" binding:"min=0"` // не больше двух знаков после запятой
+}
+
+type SpecTranslationRequest struct {
+	LanguageCode string `json:"language_code" binding:"required"`
+	Name         string `json:"name" binding:"required"`
+	Value        string `json:"value" binding:"required"`
+}
+
+// CategoryRequest - тело запроса на создание/изменение категории в админке
+type CategoryRequest struct {
+	ParentID     *uint                        `json:"parent_id"`
+	SortOrder    int                          `json:"sort_order"`
+	Translations []CategoryTranslationRequest `json:"translations" binding:"required,dive"`
+}
+
+// CategoryTranslationRequest - slug is transliterated from name when left empty
+type CategoryTranslationRequest struct {
+	LanguageCode string `json:"language_code" binding:"required"`
+	Name         string `json:"name" binding:"required"`
+	Description  string `json:"description"`
+	Slug         string `json:"slug"`
+}
+
+// PageRequest - тело запроса на изменение страницы в админке
+type PageRequest struct {
+	Slug         string                   `json:"slug" binding:"required"`
+	Template     string                   `json:"template"`
+	Translations []PageTranslationRequest `json:"translations" binding:"required,dive"`
+}
+
+type PageTranslationRequest struct {
+	LanguageCode    string `json:"language_code" binding:"required"`
+	Title           string `json:"title" binding:"required"`
+	Content         string `json:"content"`
+	MetaTitle       string `json:"meta_title"`
+	MetaDescription string `json:"meta_description"`
+}
+
+// NewsRequest - тело запроса на изменение новости в админке; окно публикации
+// меняется отдельно через NewsScheduleRequest
+type NewsRequest struct {
+	ImageURL     string                   `json:"image_url"`
+	Translations []NewsTranslationRequest `json:"translations" binding:"required,dive"`
+}
+
+type NewsTranslationRequest struct {
+	LanguageCode string `json:"language_code" binding:"required"`
+	Title        string `json:"title" binding:"required"`
+	Content      string `json:"content" binding:"required"`
+	Excerpt      string `json:"excerpt"`
+}
+
+// RevisionComparison - изменения между двумя ревизиями одного объекта
+type RevisionComparison struct {
+	From    uint          `json:"from"`
+	To      uint          `json:"to"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// ProductFilter - параметры выборки для FilterProducts
+type ProductFilter struct {
+	Locale             string
+	CategoryID         uint
+	IncludeDescendants bool
+	Search             string
+	InStock            bool
+	// Specs maps a spec name to the accepted values; values of one name are
+	// OR-ed, different names are AND-ed
+	Specs map[string][]string
+	// SpecRanges maps a spec name to a numeric range; the service converts
+	// the bounds to the base unit before the filter reaches storage
+	SpecRanges map[string]SpecRange
+	SortBy     string
+	SortOrder  string
+	Offset     int
+	Limit      int
+}
+
+// SpecFacet - значения характеристики с количеством продуктов для боковой панели фильтров
+type SpecFacet struct {
+	Name   string       `json:"name"`
+	Values []FacetValue `json:"values"`
+	// Min/Max bound the numeric values of the spec, in Unit
+	Min       *float64 `json:"min,omitempty"`
+	Max       *float64 `json:"max,omitempty"`
+	Unit      string   `json:"unit,omitempty"`
+	Dimension string   `json:"dimension,omitempty"`
+}
+
+type SpecRange struct {
+	Min  *float64
+	Max  *float64
+	Unit string
+}
+
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// StockUpdateRequest - массовое обновление остатков из админки
+type StockUpdateRequest struct {
+	Items []StockItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+// StockItemRequest - product is identified by product_id or, if it is zero, by sku
+type StockItemRequest struct {
+	ProductID uint   `json:"product_id"`
+	SKU       string `json:"sku"`
+	Warehouse string `json:"warehouse" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"min=0"`
+	Reserved  int    `json:"reserved" binding:"min=0"`
+}
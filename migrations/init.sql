@@ -1,5 +1,7 @@
 -- Enable UUID extension if needed
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+-- Trigram indexes for search-as-you-type
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
 
 -- Languages
 CREATE TABLE languages (
@@ -13,11 +15,29 @@ INSERT INTO languages (code, name) VALUES
 ('en', 'English'),
 ('pl', 'Polski');
 
+-- Full-text search configuration for a language code. Postgres ships no
+-- Polish stemmer, so pl and any other language use 'simple'. Declared
+-- IMMUTABLE so that the generated search_vector columns can call it.
+CREATE FUNCTION lang_regconfig(code VARCHAR) RETURNS regconfig AS $$
+    SELECT CASE code
+        WHEN 'ru' THEN 'russian'::regconfig
+        WHEN 'en' THEN 'english'::regconfig
+        ELSE 'simple'::regconfig
+    END
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- SKU as it is compared in search: lower case, letters and digits only, so
+-- that "PRESS-H200", "press h200" and "PRESSH200" are the same key.
+CREATE FUNCTION sku_key(sku TEXT) RETURNS TEXT AS $$
+    SELECT regexp_replace(LOWER(sku), '[^[:alnum:]]+', '', 'g')
+$$ LANGUAGE SQL IMMUTABLE;
+
 -- Pages
 CREATE TABLE pages (
     page_id SERIAL PRIMARY KEY,
     slug VARCHAR(255) UNIQUE NOT NULL,
     template VARCHAR(100) NOT NULL DEFAULT 'default',
+    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'in_review', 'published', 'archived')),
     created_at TIMESTAMP DEFAULT NOW(),
     updated_at TIMESTAMP DEFAULT NOW()
 );
@@ -30,6 +50,12 @@ CREATE TABLE page_translations (
     content TEXT,
     meta_title VARCHAR(255),
     meta_description TEXT,
+    search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector(lang_regconfig(language_code), COALESCE(title, '')), 'A') ||
+        setweight(to_tsvector(lang_regconfig(language_code), COALESCE(meta_description, '')), 'B') ||
+        -- content is a JSON document: index its string values, not its keys
+        setweight(jsonb_to_tsvector(lang_regconfig(language_code), COALESCE(NULLIF(content, ''), '{}')::jsonb, '["string"]'), 'C')
+    ) STORED,
     PRIMARY KEY (page_id, language_code)
 );
 
@@ -47,17 +73,23 @@ CREATE TABLE product_category_translations (
     language_code VARCHAR(10) REFERENCES languages(code),
     name VARCHAR(255) NOT NULL,
     description TEXT,
-    PRIMARY KEY (category_id, language_code)
+    slug VARCHAR(255) NOT NULL,
+    PRIMARY KEY (category_id, language_code),
+    UNIQUE (language_code, slug)
 );
 
 -- Products
 CREATE TABLE products (
     product_id SERIAL PRIMARY KEY,
     category_id INT REFERENCES product_categories(category_id),
+    parent_id INT REFERENCES products(product_id) ON DELETE CASCADE, -- set for variants of a configurable product
     sku VARCHAR(100) UNIQUE NOT NULL,
     image_url VARCHAR(500),
     file_url VARCHAR(500),
     sort_order INT DEFAULT 0,
+    price_on_request BOOLEAN NOT NULL DEFAULT false,
+    lead_time_days INT NOT NULL DEFAULT 0, -- manufacturing lead time when nothing is in stock
+    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'in_review', 'published', 'archived')),
     created_at TIMESTAMP DEFAULT NOW(),
     updated_at TIMESTAMP DEFAULT NOW()
 );
@@ -69,6 +101,11 @@ CREATE TABLE product_translations (
     name VARCHAR(255) NOT NULL,
     description TEXT,
     short_description TEXT,
+    search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector(lang_regconfig(language_code), COALESCE(name, '')), 'A') ||
+        setweight(to_tsvector(lang_regconfig(language_code), COALESCE(short_description, '')), 'B') ||
+        setweight(to_tsvector(lang_regconfig(language_code), COALESCE(description, '')), 'C')
+    ) STORED,
     PRIMARY KEY (product_id, language_code)
 );
 
@@ -77,6 +114,9 @@ CREATE TABLE product_specs (
     spec_id SERIAL PRIMARY KEY,
     product_id INT REFERENCES products(product_id) ON DELETE CASCADE,
     sort_order INT DEFAULT 0,
+    numeric_value DOUBLE PRECISION, -- in the base unit of the dimension: kg, kW, mm
+    unit VARCHAR(20),               -- unit the value was entered in
+    dimension VARCHAR(20),          -- 'mass', 'power', 'length'
     created_at TIMESTAMP DEFAULT NOW()
 );
 
@@ -89,13 +129,128 @@ CREATE TABLE product_spec_translations (
     PRIMARY KEY (spec_id, language_code)
 );
 
+-- Option axes of a configurable product (voltage, table size, control system)
+CREATE TABLE product_options (
+    option_id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL REFERENCES products(product_id) ON DELETE CASCADE,
+    code VARCHAR(50) NOT NULL,
+    sort_order INT DEFAULT 0,
+    UNIQUE (product_id, code)
+);
+
+CREATE TABLE product_option_translations (
+    option_id INT REFERENCES product_options(option_id) ON DELETE CASCADE,
+    language_code VARCHAR(10) REFERENCES languages(code),
+    name VARCHAR(255) NOT NULL,
+    PRIMARY KEY (option_id, language_code)
+);
+
+CREATE TABLE product_option_values (
+    value_id SERIAL PRIMARY KEY,
+    option_id INT NOT NULL REFERENCES product_options(option_id) ON DELETE CASCADE,
+    code VARCHAR(50) NOT NULL,
+    sort_order INT DEFAULT 0,
+    UNIQUE (option_id, code)
+);
+
+CREATE TABLE product_option_value_translations (
+    value_id INT REFERENCES product_option_values(value_id) ON DELETE CASCADE,
+    language_code VARCHAR(10) REFERENCES languages(code),
+    label VARCHAR(255) NOT NULL,
+    PRIMARY KEY (value_id, language_code)
+);
+
+-- Option value chosen by each variant, one per axis
+CREATE TABLE product_variant_values (
+    product_id INT REFERENCES products(product_id) ON DELETE CASCADE,
+    option_id INT REFERENCES product_options(option_id) ON DELETE CASCADE,
+    value_id INT NOT NULL REFERENCES product_option_values(value_id) ON DELETE CASCADE,
+    PRIMARY KEY (product_id, option_id)
+);
+
+-- Price lists, one per currency
+CREATE TABLE price_lists (
+    price_list_id SERIAL PRIMARY KEY,
+    code VARCHAR(50) UNIQUE NOT NULL,
+    currency VARCHAR(3) UNIQUE NOT NULL, -- ISO 4217: 'RUB', 'EUR', 'PLN'
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Price list shown for each site language
+CREATE TABLE price_list_locales (
+    language_code VARCHAR(10) PRIMARY KEY REFERENCES languages(code),
+    price_list_id INT NOT NULL REFERENCES price_lists(price_list_id) ON DELETE CASCADE
+);
+
+-- Product prices; rows with min_quantity > 1 are B2B quantity tier breaks
+CREATE TABLE product_prices (
+    price_list_id INT REFERENCES price_lists(price_list_id) ON DELETE CASCADE,
+    product_id INT REFERENCES products(product_id) ON DELETE CASCADE,
+    min_quantity INT NOT NULL DEFAULT 1 CHECK (min_quantity >= 1),
+    amount NUMERIC(14, 2) NOT NULL CHECK (amount >= 0),
+    PRIMARY KEY (price_list_id, product_id, min_quantity)
+);
+
+-- Product gallery: images, video links and downloadable files.
+-- Rows with a language_code are shown only to that language (e.g. the Polish manual).
+CREATE TABLE product_media (
+    media_id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL REFERENCES products(product_id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('image', 'video', 'file')),
+    type VARCHAR(50), -- manual, cad, brochure for files
+    url VARCHAR(500) NOT NULL,
+    language_code VARCHAR(10) REFERENCES languages(code),
+    is_primary BOOLEAN DEFAULT false,
+    sort_order INT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE product_media_translations (
+    media_id INT REFERENCES product_media(media_id) ON DELETE CASCADE,
+    language_code VARCHAR(10) REFERENCES languages(code),
+    caption VARCHAR(500),
+    PRIMARY KEY (media_id, language_code)
+);
+
+-- Manually curated links shown first among related products
+CREATE TABLE product_links (
+    product_id INT REFERENCES products(product_id) ON DELETE CASCADE,
+    linked_product_id INT REFERENCES products(product_id) ON DELETE CASCADE,
+    link_type VARCHAR(20) NOT NULL CHECK (link_type IN ('accessory', 'replacement')),
+    sort_order INT DEFAULT 0,
+    PRIMARY KEY (product_id, linked_product_id),
+    CHECK (product_id <> linked_product_id)
+);
+
+-- Warehouses
+CREATE TABLE warehouses (
+    warehouse_id SERIAL PRIMARY KEY,
+    code VARCHAR(50) UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    sort_order INT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Stock per product and warehouse; available = quantity - reserved
+CREATE TABLE product_stocks (
+    product_id INT REFERENCES products(product_id) ON DELETE CASCADE,
+    warehouse_id INT REFERENCES warehouses(warehouse_id) ON DELETE CASCADE,
+    quantity INT NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    reserved INT NOT NULL DEFAULT 0 CHECK (reserved >= 0),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (product_id, warehouse_id)
+);
+
 -- News
 CREATE TABLE news (
     news_id SERIAL PRIMARY KEY,
     image_url VARCHAR(500),
-    published BOOLEAN DEFAULT true,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'in_review', 'published', 'archived')),
+    publish_at TIMESTAMPTZ, -- published news go live at publish_at, or at once when it is NULL
+    unpublish_at TIMESTAMPTZ, -- and are hidden again from unpublish_at
     created_at TIMESTAMP DEFAULT NOW(),
-    updated_at TIMESTAMP DEFAULT NOW()
+    updated_at TIMESTAMP DEFAULT NOW(),
+    CHECK (unpublish_at IS NULL OR publish_at IS NULL OR unpublish_at > publish_at)
 );
 
 -- News translations
@@ -105,6 +260,11 @@ CREATE TABLE news_translations (
     title VARCHAR(255) NOT NULL,
     content TEXT NOT NULL,
     excerpt TEXT,
+    search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector(lang_regconfig(language_code), COALESCE(title, '')), 'A') ||
+        setweight(to_tsvector(lang_regconfig(language_code), COALESCE(excerpt, '')), 'B') ||
+        setweight(to_tsvector(lang_regconfig(language_code), COALESCE(content, '')), 'C')
+    ) STORED,
     PRIMARY KEY (news_id, language_code)
 );
 
@@ -113,6 +273,7 @@ CREATE TABLE documents (
     document_id SERIAL PRIMARY KEY,
     file_url VARCHAR(500) NOT NULL,
     type VARCHAR(50) NOT NULL, -- 'gost', 'certificate', 'reference'
+    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'in_review', 'published', 'archived')),
     created_at TIMESTAMP DEFAULT NOW()
 );
 
@@ -122,6 +283,10 @@ CREATE TABLE document_translations (
     language_code VARCHAR(10) REFERENCES languages(code),
     title VARCHAR(255) NOT NULL,
     description TEXT,
+    search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector(lang_regconfig(language_code), COALESCE(title, '')), 'A') ||
+        setweight(to_tsvector(lang_regconfig(language_code), COALESCE(description, '')), 'B')
+    ) STORED,
     PRIMARY KEY (document_id, language_code)
 );
 
@@ -154,20 +319,53 @@ CREATE TABLE feedback (
     processed BOOLEAN DEFAULT false
 );
 
+-- Revisions: a snapshot of a page, product or news item after every admin write
+CREATE TABLE revisions (
+    revision_id SERIAL PRIMARY KEY,
+    entity_type VARCHAR(20) NOT NULL CHECK (entity_type IN ('page', 'product', 'news')),
+    entity_id INT NOT NULL, -- no foreign key: history outlives the entity
+    author VARCHAR(100) NOT NULL DEFAULT '',
+    snapshot JSONB NOT NULL,
+    diff JSONB, -- changes against the previous revision, NULL for the first one
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
 -- Indexes for better performance
 CREATE INDEX idx_pages_slug ON pages(slug);
 CREATE INDEX idx_products_category ON products(category_id);
 CREATE INDEX idx_products_sku ON products(sku);
-CREATE INDEX idx_news_published ON news(published, created_at);
+CREATE INDEX idx_products_parent ON products(parent_id);
+CREATE INDEX idx_product_specs_numeric ON product_specs(dimension, numeric_value) WHERE numeric_value IS NOT NULL;
+CREATE INDEX idx_product_prices_product ON product_prices(product_id, price_list_id, min_quantity);
+CREATE INDEX idx_product_spec_translations_name ON product_spec_translations(language_code, name);
+CREATE INDEX idx_product_media_product ON product_media(product_id, sort_order);
+CREATE UNIQUE INDEX idx_product_media_primary ON product_media(product_id, COALESCE(language_code, '')) WHERE is_primary;
+CREATE INDEX idx_product_stock_available ON product_stocks(product_id) WHERE quantity > reserved;
+CREATE INDEX idx_news_status ON news(status, created_at);
+CREATE INDEX idx_news_publish_at ON news(publish_at) WHERE publish_at IS NOT NULL;
+CREATE INDEX idx_news_unpublish_at ON news(unpublish_at) WHERE unpublish_at IS NOT NULL;
 CREATE INDEX idx_feedback_processed ON feedback(processed, created_at);
-
-INSERT INTO pages (slug, template, created_at, updated_at) VALUES
-('home', 'homepage', NOW(), NOW()),
-('about', 'default', NOW(), NOW()),
-('contacts', 'contacts', NOW(), NOW()),
-('products', 'products', NOW(), NOW()),
-('news', 'news', NOW(), NOW()),
-('documents', 'documents', NOW(), NOW());
+CREATE INDEX idx_revisions_entity ON revisions(entity_type, entity_id, revision_id);
+CREATE INDEX idx_page_translations_search ON page_translations USING GIN (search_vector);
+CREATE INDEX idx_product_translations_search ON product_translations USING GIN (search_vector);
+CREATE INDEX idx_news_translations_search ON news_translations USING GIN (search_vector);
+CREATE INDEX idx_document_translations_search ON document_translations USING GIN (search_vector);
+CREATE INDEX idx_product_translations_name_trgm ON product_translations USING GIN (LOWER(name) gin_trgm_ops);
+CREATE INDEX idx_products_sku_trgm ON products USING GIN (LOWER(sku) gin_trgm_ops);
+CREATE INDEX idx_products_sku_key ON products (sku_key(sku) text_pattern_ops);
+CREATE INDEX idx_products_sku_key_trgm ON products USING GIN (sku_key(sku) gin_trgm_ops);
+CREATE INDEX idx_product_category_translations_name_trgm ON product_category_translations USING GIN (LOWER(name) gin_trgm_ops);
+CREATE INDEX idx_page_translations_title_trgm ON page_translations USING GIN (LOWER(title) gin_trgm_ops);
+CREATE INDEX idx_news_translations_title_trgm ON news_translations USING GIN (LOWER(title) gin_trgm_ops);
+CREATE INDEX idx_document_translations_title_trgm ON document_translations USING GIN (LOWER(title) gin_trgm_ops);
+
+INSERT INTO pages (slug, template, created_at, updated_at, status) VALUES
+('home', 'homepage', NOW(), NOW(), 'published'),
+('about', 'default', NOW(), NOW(), 'published'),
+('contacts', 'contacts', NOW(), NOW(), 'published'),
+('products', 'products', NOW(), NOW(), 'published'),
+('news', 'news', NOW(), NOW(), 'published'),
+('documents', 'documents', NOW(), NOW(), 'published');
 
 INSERT INTO page_translations (page_id, language_code, title, content, meta_title, meta_description) VALUES
 (1, 'ru', 'Главная', '{"hero": {"title": "Производство промышленного оборудования", "subtitle": "Высококачественные решения для вашего бизнеса", "cta": "Посмотреть каталог"}, "features": [{"title": "Качество", "description": "Сертифицированная продукция"}, {"title": "Опыт", "description": "Более 20 лет на рынке"}, {"title": "Поддержка", "description": "Техническое сопровождение"}]}', 'Промышленное оборудование | Главная', 'Производство промышленного оборудования высокого качества. Сертифицированная продукция, техническая поддержка.'),
@@ -221,28 +419,28 @@ INSERT INTO product_categories (parent_id, sort_order) VALUES
 (1, 2),    -- 4. Прессы (дочерняя от 1)
 (2, 1);    -- 5. Электроника (дочерняя от 2)
 
-INSERT INTO product_category_translations (category_id, language_code, name, description) VALUES
-(1, 'ru', 'Основное оборудование', 'Основные производственные линии и станки'),
-(1, 'en', 'Main equipment', 'Main production lines and machines'),
-(1, 'pl', 'Główne wyposażenie', 'Główne linie produkcyjne i maszyny'),
-(2, 'ru', 'Комплектующие', 'Запасные части и компоненты'),
-(2, 'en', 'Components', 'Spare parts and components'),
-(2, 'pl', 'Komponenty', 'Części zamienne i komponenty'),
-(3, 'ru', 'Станки', 'Металлообрабатывающие станки'),
-(3, 'en', 'Machine tools', 'Metalworking machines'),
-(3, 'pl', 'Obrabiarki', 'Obrabiarki do metalu'),
-(4, 'ru', 'Прессы', 'Гидравлические и механические прессы'),
-(4, 'en', 'Presses', 'Hydraulic and mechanical presses'),
-(4, 'pl', 'Prasy', 'Prasy hydrauliczne i mechaniczne'),
-(5, 'ru', 'Электроника', 'Системы управления и автоматизации'),
-(5, 'en', 'Electronics', 'Control and automation systems'),
-(5, 'pl', 'Elektronika', 'Systemy sterowania i automatyki');
-
-INSERT INTO products (category_id, sku, image_url, file_url, sort_order) VALUES
-(3, 'CNC-1000', '/images/products/cnc-1000.jpg', '/files/manuals/cnc-1000.pdf', 1),
-(3, 'MILL-500', '/images/products/mill-500.jpg', '/files/manuals/mill-500.pdf', 2),
-(4, 'PRESS-H200', '/images/products/press-h200.jpg', '/files/manuals/press-h200.pdf', 1),
-(5, 'CONTROL-X1', '/images/products/control-x1.jpg', '/files/manuals/control-x1.pdf', 1);
+INSERT INTO product_category_translations (category_id, language_code, name, description, slug) VALUES
+(1, 'ru', 'Основное оборудование', 'Основные производственные линии и станки', 'osnovnoe-oborudovanie'),
+(1, 'en', 'Main equipment', 'Main production lines and machines', 'main-equipment'),
+(1, 'pl', 'Główne wyposażenie', 'Główne linie produkcyjne i maszyny', 'glowne-wyposazenie'),
+(2, 'ru', 'Комплектующие', 'Запасные части и компоненты', 'komplektuyushchie'),
+(2, 'en', 'Components', 'Spare parts and components', 'components'),
+(2, 'pl', 'Komponenty', 'Części zamienne i komponenty', 'komponenty'),
+(3, 'ru', 'Станки', 'Металлообрабатывающие станки', 'stanki'),
+(3, 'en', 'Machine tools', 'Metalworking machines', 'machine-tools'),
+(3, 'pl', 'Obrabiarki', 'Obrabiarki do metalu', 'obrabiarki'),
+(4, 'ru', 'Прессы', 'Гидравлические и механические прессы', 'pressy'),
+(4, 'en', 'Presses', 'Hydraulic and mechanical presses', 'presses'),
+(4, 'pl', 'Prasy', 'Prasy hydrauliczne i mechaniczne', 'prasy'),
+(5, 'ru', 'Электроника', 'Системы управления и автоматизации', 'elektronika'),
+(5, 'en', 'Electronics', 'Control and automation systems', 'electronics'),
+(5, 'pl', 'Elektronika', 'Systemy sterowania i automatyki', 'elektronika');
+
+INSERT INTO products (category_id, sku, image_url, file_url, sort_order, price_on_request, lead_time_days, status) VALUES
+(3, 'CNC-1000', '/images/products/cnc-1000.jpg', '/files/manuals/cnc-1000.pdf', 1, false, 45, 'published'),
+(3, 'MILL-500', '/images/products/mill-500.jpg', '/files/manuals/mill-500.pdf', 2, false, 30, 'published'),
+(4, 'PRESS-H200', '/images/products/press-h200.jpg', '/files/manuals/press-h200.pdf', 1, true, 90, 'published'),
+(5, 'CONTROL-X1', '/images/products/control-x1.jpg', '/files/manuals/control-x1.pdf', 1, false, 14, 'published');
 
 INSERT INTO product_translations (product_id, language_code, name, description, short_description) VALUES
 (1, 'ru', 'Станок ЧПУ CNC-1000', 'Высокоточный станок с ЧПУ для металлообработки. Автоматическая смена инструмента, система охлаждения.', 'Станок ЧПУ для точной обработки'),
@@ -258,11 +456,11 @@ INSERT INTO product_translations (product_id, language_code, name, description,
 (4, 'en', 'CONTROL-X1 Control System', 'Digital control system for industrial equipment.', 'Next generation CNC system'),
 (4, 'pl', 'System sterowania CONTROL-X1', 'Cyfrowy system sterowania dla urządzeń przemysłowych.', 'Nowa generacja systemu CNC');
 
-INSERT INTO product_specs (product_id, sort_order) VALUES
-(1, 1),
-(1, 2),
-(1, 3),
-(1, 4);
+INSERT INTO product_specs (product_id, sort_order, numeric_value, unit, dimension) VALUES
+(1, 1, NULL, NULL, NULL),
+(1, 2, 15, 'kW', 'power'),
+(1, 3, 0.005, 'mm', 'length'),
+(1, 4, 4500, 'kg', 'mass');
 
 INSERT INTO product_spec_translations (spec_id, language_code, name, value) VALUES
 (1, 'ru', 'Макс. размер заготовки', '1000 × 800 × 600 мм'),
@@ -278,10 +476,108 @@ INSERT INTO product_spec_translations (spec_id, language_code, name, value) VALU
 (4, 'en', 'Weight', '4500 kg'),
 (4, 'pl', 'Waga', '4500 kg');
 
-INSERT INTO news (image_url, published, created_at) VALUES
-('/images/news/opening.jpg', true, NOW() - INTERVAL '5 days'),
-('/images/news/exhibition.jpg', true, NOW() - INTERVAL '10 days'),
-('/images/news/certificate.jpg', true, NOW() - INTERVAL '15 days');
+-- CONTROL-X1 is configurable by supply voltage
+INSERT INTO products (category_id, parent_id, sku, image_url, file_url, sort_order, price_on_request, lead_time_days, status) VALUES
+(5, 4, 'CONTROL-X1-230', '/images/products/control-x1.jpg', '/files/manuals/control-x1.pdf', 1, false, 14, 'published'),
+(5, 4, 'CONTROL-X1-400', '/images/products/control-x1-400.jpg', '/files/manuals/control-x1.pdf', 2, false, 21, 'published');
+
+INSERT INTO product_translations (product_id, language_code, name, description, short_description) VALUES
+(5, 'ru', 'Система управления CONTROL-X1, 230 В', 'Цифровая система управления для промышленного оборудования.', 'Система ЧПУ нового поколения'),
+(5, 'en', 'CONTROL-X1 Control System, 230 V', 'Digital control system for industrial equipment.', 'Next generation CNC system'),
+(5, 'pl', 'System sterowania CONTROL-X1, 230 V', 'Cyfrowy system sterowania dla urządzeń przemysłowych.', 'Nowa generacja systemu CNC'),
+(6, 'ru', 'Система управления CONTROL-X1, 400 В', 'Цифровая система управления для промышленного оборудования.', 'Система ЧПУ нового поколения'),
+(6, 'en', 'CONTROL-X1 Control System, 400 V', 'Digital control system for industrial equipment.', 'Next generation CNC system'),
+(6, 'pl', 'System sterowania CONTROL-X1, 400 V', 'Cyfrowy system sterowania dla urządzeń przemysłowych.', 'Nowa generacja systemu CNC');
+
+INSERT INTO product_options (product_id, code, sort_order) VALUES
+(4, 'voltage', 1);
+
+INSERT INTO product_option_translations (option_id, language_code, name) VALUES
+(1, 'ru', 'Напряжение питания'),
+(1, 'en', 'Supply voltage'),
+(1, 'pl', 'Napięcie zasilania');
+
+INSERT INTO product_option_values (option_id, code, sort_order) VALUES
+(1, '230v', 1),
+(1, '400v', 2);
+
+INSERT INTO product_option_value_translations (value_id, language_code, label) VALUES
+(1, 'ru', '230 В'),
+(1, 'en', '230 V'),
+(1, 'pl', '230 V'),
+(2, 'ru', '400 В'),
+(2, 'en', '400 V'),
+(2, 'pl', '400 V');
+
+INSERT INTO product_variant_values (product_id, option_id, value_id) VALUES
+(5, 1, 1),
+(6, 1, 2);
+
+INSERT INTO price_lists (code, currency) VALUES
+('retail-rub', 'RUB'),
+('retail-eur', 'EUR'),
+('retail-pln', 'PLN');
+
+INSERT INTO price_list_locales (language_code, price_list_id) VALUES
+('ru', 1),
+('en', 2),
+('pl', 3);
+
+INSERT INTO product_prices (price_list_id, product_id, min_quantity, amount) VALUES
+(1, 1, 1, 4850000.00),
+(2, 1, 1, 52000.00),
+(3, 1, 1, 224000.00),
+(1, 2, 1, 2100000.00),
+(2, 2, 1, 22500.00),
+(3, 2, 1, 97000.00),
+(1, 4, 1, 185000.00),
+(1, 4, 10, 168000.00),
+(2, 4, 1, 1990.00),
+(2, 4, 10, 1790.00),
+(3, 4, 1, 8600.00),
+(3, 4, 10, 7750.00);
+
+INSERT INTO product_media (product_id, kind, type, url, language_code, is_primary, sort_order) VALUES
+(1, 'image', NULL, '/images/products/cnc-1000.jpg', NULL, true, 1),
+(1, 'image', NULL, '/images/products/cnc-1000-table.jpg', NULL, false, 2),
+(1, 'video', NULL, 'https://www.youtube.com/watch?v=cnc1000', NULL, false, 3),
+(1, 'file', 'manual', '/files/manuals/cnc-1000-ru.pdf', 'ru', false, 4),
+(1, 'file', 'manual', '/files/manuals/cnc-1000-en.pdf', 'en', false, 4),
+(1, 'file', 'manual', '/files/manuals/cnc-1000-pl.pdf', 'pl', false, 4),
+(1, 'file', 'cad', '/files/cad/cnc-1000.dwg', NULL, false, 5);
+
+INSERT INTO product_media_translations (media_id, language_code, caption) VALUES
+(1, 'ru', 'Станок CNC-1000'),
+(1, 'en', 'CNC-1000 machine'),
+(1, 'pl', 'Maszyna CNC-1000'),
+(3, 'ru', 'Видеообзор станка'),
+(3, 'en', 'Machine video overview'),
+(3, 'pl', 'Prezentacja wideo maszyny'),
+(4, 'ru', 'Руководство по эксплуатации'),
+(5, 'en', 'Operating manual'),
+(6, 'pl', 'Instrukcja obsługi'),
+(7, 'ru', 'Чертёж DWG'),
+(7, 'en', 'DWG drawing'),
+(7, 'pl', 'Rysunek DWG');
+
+INSERT INTO product_links (product_id, linked_product_id, link_type, sort_order) VALUES
+(1, 4, 'accessory', 1),
+(2, 4, 'accessory', 1);
+
+INSERT INTO warehouses (code, name, sort_order) VALUES
+('msk', 'Moscow', 1),
+('wro', 'Wrocław', 2);
+
+INSERT INTO product_stocks (product_id, warehouse_id, quantity, reserved) VALUES
+(1, 1, 2, 1),
+(2, 1, 0, 0),
+(4, 1, 25, 5),
+(4, 2, 12, 0);
+
+INSERT INTO news (image_url, status, created_at) VALUES
+('/images/news/opening.jpg', 'published', NOW() - INTERVAL '5 days'),
+('/images/news/exhibition.jpg', 'published', NOW() - INTERVAL '10 days'),
+('/images/news/certificate.jpg', 'published', NOW() - INTERVAL '15 days');
 
 INSERT INTO news_translations (news_id, language_code, title, content, excerpt) VALUES
 (1, 'ru', 'Открытие нового цеха', 'Состоялось торжественное открытие нового производственного цеха площадью 5000 кв.м.', 'Новый цех увеличит производственные мощности'),
@@ -294,10 +590,10 @@ INSERT INTO news_translations (news_id, language_code, title, content, excerpt)
 (3, 'en', 'New certificate received', 'Received ISO 9001:2024 quality certificate.', 'Confirmation of our product quality'),
 (3, 'pl', 'Otrzymano nowy certyfikat', 'Otrzymano certyfikat jakości ISO 9001:2024.', 'Potwierdzenie jakości naszych produktów');
 
-INSERT INTO documents (file_url, type, created_at) VALUES
-('/files/gosts/gost-12345.pdf', 'gost', NOW()),
-('/files/certificates/iso-9001.pdf', 'certificate', NOW()),
-('/files/references/technical-guide.pdf', 'reference', NOW());
+INSERT INTO documents (file_url, type, created_at, status) VALUES
+('/files/gosts/gost-12345.pdf', 'gost', NOW(), 'published'),
+('/files/certificates/iso-9001.pdf', 'certificate', NOW(), 'published'),
+('/files/references/technical-guide.pdf', 'reference', NOW(), 'published');
 
 INSERT INTO document_translations (document_id, language_code, title, description) VALUES
 (1, 'ru', 'ГОСТ 12345-2024', 'Стандарт на промышленное оборудование'),
@@ -309,3 +605,26 @@ INSERT INTO document_translations (document_id, language_code, title, descriptio
 (3, 'ru', 'Технический справочник', 'Руководство по эксплуатации оборудования'),
 (3, 'en', 'Technical Reference Guide', 'Equipment operation manual'),
 (3, 'pl', 'Poradnik techniczny', 'Instrukcja obsługi sprzętu');
+
+-- Words of published product names and spec names per language: the
+-- vocabulary "did you mean" suggestions are picked from. Refreshed by the
+-- back end every service.vocabulary_interval.
+CREATE MATERIALIZED VIEW search_vocabulary AS
+SELECT language_code, word, COUNT(*) AS frequency
+FROM (
+    SELECT t.language_code, regexp_split_to_table(LOWER(t.name), '[^[:alnum:]]+') AS word
+    FROM product_translations t
+    JOIN products p ON p.product_id = t.product_id
+    WHERE p.status = 'published'
+    UNION ALL
+    SELECT st.language_code, regexp_split_to_table(LOWER(st.name), '[^[:alnum:]]+')
+    FROM product_spec_translations st
+    JOIN product_specs s ON s.spec_id = st.spec_id
+    JOIN products p ON p.product_id = s.product_id
+    WHERE p.status = 'published'
+) words
+WHERE LENGTH(word) >= 3
+GROUP BY language_code, word;
+
+CREATE UNIQUE INDEX idx_search_vocabulary_word ON search_vocabulary(language_code, word);
+CREATE INDEX idx_search_vocabulary_trgm ON search_vocabulary USING GIN (word gin_trgm_ops);
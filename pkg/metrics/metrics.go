@@ -9,6 +9,7 @@ import (
 type Metrics struct {
 	RequestsTotal        *prometheus.CounterVec
 	RequestLatency       *prometheus.HistogramVec
+	SuggestLatency       prometheus.Histogram
 	CacheOperationsTotal prometheus.Counter
 	CacheHitCounter      prometheus.Counter
 	CacheMissCounter     prometheus.Counter
@@ -33,6 +34,13 @@ func NewMetrics() *Metrics {
 			},
 			[]string{"method", "path"},
 		),
+		SuggestLatency: promauto.NewHistogram(
+			prometheus.HistogramOpts{
+				Name:    "p2p_message_service_search_suggest_duration_seconds",
+				Help:    "Search suggestion latency distribution, target under 20 ms",
+				Buckets: []float64{.0025, .005, .01, .02, .05, .1, .25},
+			},
+		),
 		CacheHitCounter: promauto.NewCounter(
 			prometheus.CounterOpts{
 				Name: "p2p_message_service_cache_hits_total",
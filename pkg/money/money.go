@@ -0,0 +1,122 @@
+package money
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Amount is a sum of money in minor units (cents, kopecks, grosze). It is
+// written to JSON and to NUMERIC(14,2) columns as a decimal with two digits.
+type Amount int64
+
+// Parse reads a decimal amount such as "12", "12.5" or "12.50". More than two
+// decimal places are rejected rather than rounded.
+func Parse(text string) (Amount, error) {
+	text = strings.TrimSpace(text)
+
+	negative := strings.HasPrefix(text, "-")
+	whole, fraction, _ := strings.Cut(strings.TrimPrefix(text, "-"), ".")
+
+	if whole == "" || !digitsOnly(whole) || !digitsOnly(fraction) {
+		return 0, fmt.Errorf("money: invalid amount %q", text)
+	}
+
+	if len(fraction) > 2 {
+		return 0, fmt.Errorf("money: amount %q has more than two decimal places", text)
+	}
+
+	fraction += strings.Repeat("0", 2-len(fraction))
+
+	units, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("money: amount %q is out of range", text)
+	}
+
+	if negative {
+		units = -units
+	}
+
+	return Amount(units), nil
+}
+
+func digitsOnly(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// String formats the amount with exactly two decimal places.
+func (a Amount) String() string {
+	sign := ""
+	units := int64(a)
+
+	if units < 0 {
+		sign, units = "-", -units
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, units/100, units%100)
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number, or a string holding one.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		return nil
+	}
+
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+
+	parsed, err := Parse(text)
+	if err != nil {
+		return err
+	}
+
+	*a = parsed
+
+	return nil
+}
+
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// Scan reads a NUMERIC column, which drivers return as text.
+func (a *Amount) Scan(src any) error {
+	switch value := src.(type) {
+	case nil:
+		*a = 0
+		return nil
+	case []byte:
+		return a.scanText(string(value))
+	case string:
+		return a.scanText(value)
+	case int64:
+		*a = Amount(value * 100)
+		return nil
+	default:
+		return fmt.Errorf("money: cannot scan %T into Amount", src)
+	}
+}
+
+func (a *Amount) scanText(text string) error {
+	parsed, err := Parse(text)
+	if err != nil {
+		return err
+	}
+
+	*a = parsed
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    Amount
+		wantErr bool
+	}{
+		{text: "12", want: 1200},
+		{text: "12.5", want: 1250},
+		{text: "12.50", want: 1250},
+		{text: "0.07", want: 7},
+		{text: " 199999.99 ", want: 19999999},
+		{text: "-3.10", want: -310},
+		{text: "12.", want: 1200},
+		{text: "0.1 ", want: 10},
+		{text: "12.345", wantErr: true},
+		{text: "0.001", wantErr: true},
+		{text: "1e3", wantErr: true},
+		{text: "12,50", wantErr: true},
+		{text: ".5", wantErr: true},
+		{text: "", wantErr: true},
+		{text: "99999999999999999999", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := Parse(tt.text)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse(%q) = %v, want an error", tt.text, got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.text, err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %d, want %d", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		amount Amount
+		want   string
+	}{
+		{0, "0.00"},
+		{7, "0.07"},
+		{1250, "12.50"},
+		{19999999, "199999.99"},
+		{-310, "-3.10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.amount.String(); got != tt.want {
+				t.Errorf("Amount(%d).String() = %q, want %q", tt.amount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    Amount
+		wantErr bool
+	}{
+		{name: "number", data: `{"amount": 1499.9}`, want: 149990},
+		{name: "string", data: `{"amount": "1499.90"}`, want: 149990},
+		{name: "too precise", data: `{"amount": 1499.999}`, wantErr: true},
+		{name: "not a number", data: `{"amount": "cheap"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var price struct {
+				Amount Amount `json:"amount"`
+			}
+
+			err := json.Unmarshal([]byte(tt.data), &price)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Unmarshal(%s) = %v, want an error", tt.data, price.Amount)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unmarshal(%s): %v", tt.data, err)
+			}
+
+			if price.Amount != tt.want {
+				t.Fatalf("Unmarshal(%s) = %d, want %d", tt.data, price.Amount, tt.want)
+			}
+
+			data, err := json.Marshal(price)
+			if err != nil {
+				t.Fatalf("Marshal(%d): %v", price.Amount, err)
+			}
+
+			var back struct {
+				Amount Amount `json:"amount"`
+			}
+
+			if err := json.Unmarshal(data, &back); err != nil || back.Amount != tt.want {
+				t.Errorf("round trip of %s = %s (%v), want %d", tt.data, data, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name string
+		src  any
+		want Amount
+	}{
+		{"numeric text", []byte("1499.90"), 149990},
+		{"string", "0.05", 5},
+		{"integer", int64(12), 1200},
+		{"null", nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Amount
+			if err := got.Scan(tt.src); err != nil {
+				t.Fatalf("Scan(%v): %v", tt.src, err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Scan(%v) = %d, want %d", tt.src, got, tt.want)
+			}
+		})
+	}
+}
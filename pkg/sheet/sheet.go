@@ -0,0 +1,141 @@
+// Package sheet reads and writes simple tables as CSV or XLSX.
+package sheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Format of a spreadsheet file.
+type Format string
+
+const (
+	CSV  Format = "csv"
+	XLSX Format = "xlsx"
+)
+
+// utf8BOM makes Excel open UTF-8 CSV files with the right encoding.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// ParseFormat accepts "csv" and "xlsx" in any case.
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimPrefix(s, "."))) {
+	case CSV:
+		return CSV, nil
+	case XLSX:
+		return XLSX, nil
+	}
+
+	return "", fmt.Errorf("unsupported format %q, want csv or xlsx", s)
+}
+
+// FormatOf detects the format from a file name extension.
+func FormatOf(name string) (Format, error) {
+	return ParseFormat(filepath.Ext(name))
+}
+
+// Read returns all rows of a CSV file or of the first XLSX sheet.
+// Rows may be shorter than the header when trailing cells are empty.
+func Read(r io.Reader, format Format) ([][]string, error) {
+	switch format {
+	case CSV:
+		return readCSV(r)
+	case XLSX:
+		return readXLSX(r)
+	}
+
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+// Write stores rows as CSV or as a single XLSX sheet.
+func Write(w io.Writer, format Format, rows [][]string) error {
+	switch format {
+	case CSV:
+		return writeCSV(w, rows)
+	case XLSX:
+		return writeXLSX(w, rows)
+	}
+
+	return fmt.Errorf("unsupported format %q", format)
+}
+
+// readCSV accepts both comma and semicolon separated files; the separator is
+// taken from the header line, as spreadsheet programs pick it by locale.
+func readCSV(r io.Reader) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimPrefix(data, utf8BOM)
+
+	header := data
+	if idx := bytes.IndexByte(header, '\n'); idx >= 0 {
+		header = header[:idx]
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+
+	if bytes.Count(header, []byte{';'}) > bytes.Count(header, []byte{','}) {
+		reader.Comma = ';'
+	}
+
+	return reader.ReadAll()
+}
+
+func writeCSV(w io.Writer, rows [][]string) error {
+	if _, err := w.Write(utf8BOM); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+
+	return writer.Error()
+}
+
+func readXLSX(r io.Reader) ([][]string, error) {
+	file, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	sheets := file.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, nil
+	}
+
+	return file.GetRows(sheets[0])
+}
+
+func writeXLSX(w io.Writer, rows [][]string) error {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	sheet := file.GetSheetName(0)
+
+	for idx, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, idx+1)
+		if err != nil {
+			return err
+		}
+
+		if err := file.SetSheetRow(sheet, cell, &row); err != nil {
+			return err
+		}
+	}
+
+	return file.Write(w)
+}
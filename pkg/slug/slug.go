@@ -0,0 +1,61 @@
+package slug
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// transliteration maps Cyrillic (ru) and Polish letters to Latin.
+var transliteration = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "",
+	'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'ą': "a", 'ć': "c", 'ę': "e", 'ł': "l", 'ń': "n", 'ó': "o", 'ś': "s",
+	'ź': "z", 'ż': "z",
+}
+
+var validSlug = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Make builds a URL slug from text: letters are transliterated to Latin,
+// lowercased, and every run of other characters becomes a single dash.
+func Make(text string) string {
+	var builder strings.Builder
+
+	dash := false
+
+	for _, r := range strings.ToLower(text) {
+		var part string
+
+		if latin, ok := transliteration[r]; ok {
+			part = latin
+		} else if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			part = string(r)
+		}
+
+		if part == "" {
+			// soft and hard signs vanish without splitting the word
+			if _, ok := transliteration[r]; !ok {
+				dash = builder.Len() > 0
+			}
+
+			continue
+		}
+
+		if dash {
+			builder.WriteByte('-')
+			dash = false
+		}
+
+		builder.WriteString(part)
+	}
+
+	return builder.String()
+}
+
+// Valid reports whether s is already a well-formed slug.
+func Valid(s string) bool {
+	return validSlug.MatchString(s)
+}
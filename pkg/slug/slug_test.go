@@ -0,0 +1,63 @@
+package slug
+
+import "testing"
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"latin", "Hydraulic Press", "hydraulic-press"},
+		{"russian", "Гидравлический пресс", "gidravlicheskiy-press"},
+		{"russian digraphs", "Щётка для чистки", "shchetka-dlya-chistki"},
+		{"soft and hard signs", "Объём пыльника", "obem-pylnika"},
+		{"polish", "Łódź żółć", "lodz-zolc"},
+		{"polish uppercase", "ŚRUBA Ćwierćcalowa", "sruba-cwierccalowa"},
+		{"digits", "Насос 12V / 3000 об/мин", "nasos-12v-3000-ob-min"},
+		{"punctuation runs", "  --Pump!!  (v2)--  ", "pump-v2"},
+		{"other scripts", "Pompa 泵 wody", "pompa-wody"},
+		{"empty", "", ""},
+		{"nothing to keep", "!!! ---", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Make(tt.text)
+			if got != tt.want {
+				t.Fatalf("Make(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+
+			if got != "" && !Valid(got) {
+				t.Errorf("Make(%q) = %q, which is not a valid slug", tt.text, got)
+			}
+		})
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		slug string
+		want bool
+	}{
+		{"hydraulic-press", true},
+		{"pump", true},
+		{"12v-pump-3000", true},
+		{"", false},
+		{"Hydraulic-Press", false},
+		{"-pump", false},
+		{"pump-", false},
+		{"pump--press", false},
+		{"pump_press", false},
+		{"pump press", false},
+		{"насос", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.slug, func(t *testing.T) {
+			if got := Valid(tt.slug); got != tt.want {
+				t.Errorf("Valid(%q) = %v, want %v", tt.slug, got, tt.want)
+			}
+		})
+	}
+}
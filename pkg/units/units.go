@@ -0,0 +1,114 @@
+package units
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Dimension is the physical quantity a unit measures.
+type Dimension string
+
+const (
+	Mass   Dimension = "mass"
+	Power  Dimension = "power"
+	Length Dimension = "length"
+)
+
+// Unit is a known unit with its factor to the base unit of its dimension.
+type Unit struct {
+	Symbol    string
+	Dimension Dimension
+	Factor    float64
+}
+
+var (
+	kilogram   = Unit{"kg", Mass, 1}
+	tonne      = Unit{"t", Mass, 1000}
+	pound      = Unit{"lb", Mass, 0.45359237}
+	kilowatt   = Unit{"kW", Power, 1}
+	watt       = Unit{"W", Power, 0.001}
+	horsepower = Unit{"hp", Power, 0.745699872}
+	millimetre = Unit{"mm", Length, 1}
+	centimetre = Unit{"cm", Length, 10}
+	metre      = Unit{"m", Length, 1000}
+	inch       = Unit{"in", Length, 25.4}
+)
+
+// base units: values stored in the database are expressed in these
+var base = map[Dimension]Unit{
+	Mass:   kilogram,
+	Power:  kilowatt,
+	Length: millimetre,
+}
+
+// aliases are matched case-insensitively and cover ru/pl/en spellings.
+var aliases = map[string]Unit{
+	"kg": kilogram, "кг": kilogram,
+	"t": tonne, "т": tonne, "ton": tonne, "tons": tonne, "tonne": tonne, "tonnes": tonne, "тонн": tonne,
+	"lb": pound, "lbs": pound,
+	"kw": kilowatt, "квт": kilowatt,
+	"w": watt, "вт": watt,
+	"hp": horsepower, "л.с.": horsepower,
+	"mm": millimetre, "мм": millimetre,
+	"cm": centimetre, "см": centimetre,
+	"m": metre, "м": metre,
+	"in": inch, "inch": inch, "inches": inch, `"`: inch, "″": inch,
+}
+
+var singleValue = regexp.MustCompile(`^\s*[±~≈]?\s*(\d+(?:[.,]\d+)?)\s*(\S+(?:\s\S+)?)\s*$`)
+
+// Lookup resolves a unit symbol or alias.
+func Lookup(symbol string) (Unit, bool) {
+	unit, ok := aliases[strings.ToLower(strings.TrimSpace(symbol))]
+	return unit, ok
+}
+
+// Base returns the base unit of the dimension.
+func Base(dimension Dimension) (Unit, bool) {
+	unit, ok := base[dimension]
+	return unit, ok
+}
+
+// ToBase converts value given in unit to the base unit of its dimension.
+func ToBase(value float64, unit Unit) float64 {
+	return value * unit.Factor
+}
+
+// FromBase converts value given in the base unit to unit.
+func FromBase(value float64, unit Unit) float64 {
+	return value / unit.Factor
+}
+
+// Round rounds value to the given number of significant digits, so that
+// small and large values keep the same relative precision.
+func Round(value float64, digits int) float64 {
+	rounded, err := strconv.ParseFloat(strconv.FormatFloat(value, 'g', digits, 64), 64)
+	if err != nil {
+		return value
+	}
+
+	return rounded
+}
+
+// Parse extracts a single quantity such as "4500 kg" or "15 кВт" from free
+// text. Composite values like "1000 × 800 × 600 mm" are rejected.
+func Parse(text string) (float64, Unit, error) {
+	match := singleValue.FindStringSubmatch(text)
+	if match == nil {
+		return 0, Unit{}, fmt.Errorf("%q is not a single quantity", text)
+	}
+
+	unit, ok := Lookup(match[2])
+	if !ok {
+		return 0, Unit{}, fmt.Errorf("unknown unit %q", match[2])
+	}
+
+	value, err := strconv.ParseFloat(strings.ReplaceAll(match[1], ",", "."), 64)
+	if err != nil {
+		return 0, Unit{}, err
+	}
+
+	return value, unit, nil
+}
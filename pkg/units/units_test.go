@@ -0,0 +1,120 @@
+package units
+
+import "testing"
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  float64
+		digits int
+		want   float64
+	}{
+		{"whole number", 4500, 4, 4500},
+		{"large value", 123456, 4, 123500},
+		{"small value", 0.0123456, 4, 0.01235},
+		{"conversion noise", 9920.9999999, 4, 9921},
+		{"inches", 39.37007874, 4, 39.37},
+		{"zero", 0, 4, 0},
+		{"negative", -1.23456, 3, -1.23},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Round(tt.value, tt.digits); got != tt.want {
+				t.Errorf("Round(%v, %d) = %v, want %v", tt.value, tt.digits, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text    string
+		value   float64
+		unit    Unit
+		wantErr bool
+	}{
+		{text: "4500 kg", value: 4500, unit: kilogram},
+		{text: "15 кВт", value: 15, unit: kilowatt},
+		{text: "2,5 т", value: 2.5, unit: tonne},
+		{text: "≈ 12 inches", value: 12, unit: inch},
+		{text: "  800mm ", value: 800, unit: millimetre},
+		{text: "10 л.с.", value: 10, unit: horsepower},
+		{text: "1000 × 800 × 600 mm", wantErr: true},
+		{text: "12 parsecs", wantErr: true},
+		{text: "heavy", wantErr: true},
+		{text: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			value, unit, err := Parse(tt.text)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse(%q) = %v %s, want an error", tt.text, value, unit.Symbol)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.text, err)
+			}
+
+			if value != tt.value || unit != tt.unit {
+				t.Errorf("Parse(%q) = %v %s, want %v %s", tt.text, value, unit.Symbol, tt.value, tt.unit.Symbol)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		symbol string
+		want   Unit
+		ok     bool
+	}{
+		{"kg", kilogram, true},
+		{"KG", kilogram, true},
+		{" мм ", millimetre, true},
+		{`"`, inch, true},
+		{"lbs", pound, true},
+		{"furlong", Unit{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.symbol, func(t *testing.T) {
+			got, ok := Lookup(tt.symbol)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("Lookup(%q) = %v, %v, want %v, %v", tt.symbol, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestBaseRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		value float64
+		unit  Unit
+		base  float64
+	}{
+		{"tonnes to kilograms", 2.5, tonne, 2500},
+		{"watts to kilowatts", 1500, watt, 1.5},
+		{"metres to millimetres", 1.2, metre, 1200},
+		{"inches to millimetres", 10, inch, 254},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := ToBase(tt.value, tt.unit)
+			if Round(base, 10) != tt.base {
+				t.Fatalf("ToBase(%v, %s) = %v, want %v", tt.value, tt.unit.Symbol, base, tt.base)
+			}
+
+			if back := FromBase(base, tt.unit); Round(back, 10) != tt.value {
+				t.Errorf("FromBase(%v, %s) = %v, want %v", base, tt.unit.Symbol, back, tt.value)
+			}
+		})
+	}
+}